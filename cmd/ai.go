@@ -0,0 +1,19 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// aiCmd represents the ai command
+var aiCmd = &cobra.Command{
+	Use:   "ai",
+	Short: "Tools for the AI prompts and models used by the reports",
+	Long:  ``,
+}
+
+func init() {
+	rootCmd.AddCommand(aiCmd)
+}
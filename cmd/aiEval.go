@@ -0,0 +1,57 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/edcdavid/jira-helper/internal/aieval"
+	"github.com/edcdavid/jira-helper/internal/prompts"
+	"github.com/spf13/cobra"
+)
+
+var evalFixtures, evalPrompt string
+var evalMinSimilarity float64
+
+// aiEvalCmd represents the ai eval command
+var aiEvalCmd = &cobra.Command{
+	Use:   "eval",
+	Short: "Scores a prompt and model pair against a set of status summary fixtures",
+	Long: `Runs the format status prompt with the given Ollama model over a fixture set of status
+summaries with expected outputs, and reports exact-match and similarity scores.
+Only the Ollama server (OLLAMA_HOST) is contacted, Jira is not needed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		set, err := aieval.LoadFixtures(evalFixtures)
+		if err != nil {
+			log.Fatalf("Cannot load fixtures, err:%v", err)
+		}
+		results, err := aieval.Run(context.Background(), set, ollamaModel, evalPrompt)
+		if err != nil {
+			log.Fatalf("Evaluation failed, err:%v", err)
+		}
+
+		promptName := prompts.FormatStatus
+		if evalPrompt != "" {
+			promptName = evalPrompt
+		}
+		mean := aieval.WriteReport(os.Stdout, ollamaModel, promptName, results)
+		if mean < evalMinSimilarity {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	aiCmd.AddCommand(aiEvalCmd)
+	aiEvalCmd.Flags().StringVarP(&ollamaModel, "ollamaModel", "m", "", "The Ollama model to evaluate")
+	aiEvalCmd.Flags().StringVarP(&evalFixtures, "fixtures", "f", "",
+		"Fixture set YAML file (default: the embedded example set)")
+	aiEvalCmd.Flags().StringVarP(&evalPrompt, "prompt", "p", "",
+		"Prompt template file overriding the embedded format status prompt")
+	aiEvalCmd.Flags().Float64Var(&evalMinSimilarity, "minSimilarity", 0,
+		"Exit with an error when the mean similarity (0 to 1) is lower")
+	_ = aiEvalCmd.MarkFlagRequired("ollamaModel")
+}
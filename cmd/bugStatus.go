@@ -4,11 +4,20 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"log"
+	"os"
+
 	"github.com/edcdavid/jira-helper/internal/reports"
 	"github.com/spf13/cobra"
 )
 
 var releaseCutoffDate, FromDate string
+var bugStatusIssues bool
+var bugStatusFailOn string
+
+// exitFailOn is the exit code of bugStatus when a filter reaches the --failOn status, apart from
+// the 1 of the errors so a CI job can tell them apart.
+const exitFailOn = 2
 
 // bugStatusCmd represents the bugStatus command
 var bugStatusCmd = &cobra.Command{
@@ -16,7 +25,16 @@ var bugStatusCmd = &cobra.Command{
 	Short: "Creates a markdown bar diagram with bug status",
 	Long:  ``,
 	Run: func(cmd *cobra.Command, args []string) {
-		reports.GetBugStatusReport(jiraURL, token, releaseCutoffDate, FromDate)
+		switch bugStatusFailOn {
+		case "", "yellow", "red":
+		default:
+			log.Fatalf("failOn %q not supported, use yellow or red", bugStatusFailOn)
+		}
+		releaseCutoffDate = currentReleaseDate(cmd, loadReleaseCalendar(), releaseCutoffDate)
+		rag := reports.GetBugStatusReport(jiraURL, token, releaseCutoffDate, FromDate, bugStatusIssues)
+		if bugStatusFailOn != "" && reports.RAGReached(rag, bugStatusFailOn) {
+			os.Exit(exitFailOn)
+		}
 	},
 }
 
@@ -25,7 +43,13 @@ func init() {
 	bugStatusCmd.Flags().StringVarP(&token, "token", "t", "", "The Personal Access Token from Jira")
 	bugStatusCmd.Flags().StringVarP(&jiraURL, "url", "u", "https://issues.redhat.com", "The Jira URL")
 	bugStatusCmd.Flags().StringVarP(&releaseCutoffDate, "releaseDate", "r", "2025-05-12",
-		"The openshift release date (for example, 2025-05-12)")
+		"The openshift release date (for example, 2025-05-12), the GA of the current release of the calendar when not set")
+	bugStatusCmd.Flags().StringVar(&releaseCalendar, "calendar", "",
+		"Release calendar YAML file replacing the embedded one, see internal/reports/calendar/releases.yml")
 	bugStatusCmd.Flags().StringVarP(&FromDate, "fromDate", "d", "2023-05-15",
 		"The date from which to consider issues created")
+	bugStatusCmd.Flags().BoolVar(&bugStatusIssues, "issues", false,
+		"List the issues of each group under the charts, collapsed when there are many")
+	bugStatusCmd.Flags().StringVar(&bugStatusFailOn, "failOn", "",
+		"Exit with code 2 when a filter status is at least yellow or red, from its thresholds (errors exit with 1)")
 }
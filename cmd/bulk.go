@@ -0,0 +1,34 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"time"
+
+	"github.com/edcdavid/jira-helper/internal/bulk"
+	"github.com/spf13/cobra"
+)
+
+var bulkOptions bulk.Options
+
+// bulkCmd represents the bulk command
+var bulkCmd = &cobra.Command{
+	Use:   "bulk",
+	Short: "Bulk changes of issues, recorded in an audit log",
+	Long:  ``,
+}
+
+func init() {
+	rootCmd.AddCommand(bulkCmd)
+	bulkCmd.PersistentFlags().StringVarP(&token, "token", "t", "", "The Personal Access Token from Jira")
+	bulkCmd.PersistentFlags().StringVarP(&jiraURL, "url", "u", "https://issues.redhat.com", "The Jira URL")
+	bulkCmd.PersistentFlags().StringVar(&bulkOptions.AuditLog, "auditLog", "jira-helper-audit.jsonl",
+		"The JSON lines file where every change is recorded")
+	bulkCmd.PersistentFlags().DurationVar(&bulkOptions.Interval, "interval", 500*time.Millisecond, //nolint:mnd
+		"Minimum time between two updates, 0 to disable the throttling")
+	bulkCmd.PersistentFlags().IntVar(&bulkOptions.Retries, "retries", 3, //nolint:mnd
+		"Retries of an update rejected by rate limiting or a server error")
+	bulkCmd.PersistentFlags().BoolVarP(&bulkOptions.Yes, "yes", "y", false, "Apply without asking for confirmation")
+	bulkCmd.PersistentFlags().BoolVar(&bulkOptions.DryRun, "dry-run", false, "Only show the plan")
+}
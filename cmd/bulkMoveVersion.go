@@ -0,0 +1,49 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/edcdavid/jira-helper/internal/bulk"
+	"github.com/edcdavid/jira-helper/internal/jirahelper"
+	"github.com/spf13/cobra"
+)
+
+var fromVersion, toVersion string
+
+// bulkMoveVersionCmd represents the bulk move-version command
+var bulkMoveVersionCmd = &cobra.Command{
+	Use:   "move-version",
+	Short: "Moves issues from one fixVersion to another",
+	Long: `Shows the plan of the issues moving from one fixVersion to another, asks for confirmation,
+then applies the changes and records them in the audit log, which "bulk undo" can revert.
+
+Example:
+  jira-helper bulk move-version --from openshift-4.20 --to openshift-4.21 \
+    -f "project = CNF and fixVersion = openshift-4.20 and statusCategory != Done"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := jirahelper.NewClient(jiraURL, token)
+		if err != nil {
+			log.Fatal(err)
+		}
+		err = bulk.MoveVersion(context.Background(), client, issueFilter, fromVersion, toVersion, &bulkOptions,
+			os.Stdin, os.Stdout)
+		if err != nil {
+			log.Fatalf("Move failed, err:%v", err)
+		}
+	},
+}
+
+func init() {
+	bulkCmd.AddCommand(bulkMoveVersionCmd)
+	bulkMoveVersionCmd.Flags().StringVarP(&issueFilter, "issueFilter", "f", "",
+		"The Jira jql filter query (default: the unresolved issues of the --from version)")
+	bulkMoveVersionCmd.Flags().StringVar(&fromVersion, "from", "", "The fixVersion to remove (for example, openshift-4.20)")
+	bulkMoveVersionCmd.Flags().StringVar(&toVersion, "to", "", "The fixVersion to add (for example, openshift-4.21)")
+	_ = bulkMoveVersionCmd.MarkFlagRequired("from")
+	_ = bulkMoveVersionCmd.MarkFlagRequired("to")
+}
@@ -0,0 +1,38 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/edcdavid/jira-helper/internal/bulk"
+	"github.com/edcdavid/jira-helper/internal/jirahelper"
+	"github.com/spf13/cobra"
+)
+
+var undoRunID string
+
+// bulkUndoCmd represents the bulk undo command
+var bulkUndoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Reverts a bulk change recorded in the audit log",
+	Long: `Replays the changes of a run recorded in the audit log in reverse order. By default the
+last move-version run is reverted.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := jirahelper.NewClient(jiraURL, token)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := bulk.Undo(context.Background(), client, undoRunID, &bulkOptions, os.Stdin, os.Stdout); err != nil {
+			log.Fatalf("Undo failed, err:%v", err)
+		}
+	},
+}
+
+func init() {
+	bulkCmd.AddCommand(bulkUndoCmd)
+	bulkUndoCmd.Flags().StringVar(&undoRunID, "run", "", "The run id to revert (default: the last move-version run)")
+}
@@ -0,0 +1,59 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"log"
+	"time"
+
+	"github.com/edcdavid/jira-helper/internal/reports"
+	"github.com/spf13/cobra"
+)
+
+var releaseCalendar string
+
+// loadReleaseCalendar loads the --calendar file, or the embedded release calendar.
+func loadReleaseCalendar() reports.ReleaseCalendar {
+	calendar, err := reports.LoadReleaseCalendar(releaseCalendar)
+	if err != nil {
+		log.Fatalf("Cannot load release calendar, err:%v", err)
+	}
+	return calendar
+}
+
+// currentRelease returns the current release of the calendar when the release flag is not
+// set, and the flag value otherwise, or when the issueFilter query replaces the release query.
+// It exits when the calendar has no current release rather than falling back on the flag
+// default.
+func currentRelease(cmd *cobra.Command, calendar reports.ReleaseCalendar, value string) string {
+	if cmd.Flags().Changed("release") || cmd.Flags().Changed("issueFilter") {
+		return value
+	}
+	current := mustCurrent(calendar, "release")
+	log.Printf("Using release %s, the current release of the calendar", current.Release)
+	return current.Release
+}
+
+// currentReleaseDate returns the GA date of the current release of the calendar when the
+// releaseDate flag is not set, and the flag value otherwise. It exits when the calendar has no
+// current release rather than falling back on the flag default.
+func currentReleaseDate(cmd *cobra.Command, calendar reports.ReleaseCalendar, value string) string {
+	if cmd.Flags().Changed("releaseDate") {
+		return value
+	}
+	current := mustCurrent(calendar, "releaseDate")
+	ga, _ := current.GA()
+	log.Printf("Using release date %s, the GA of release %s of the calendar", ga.Format(time.DateOnly), current.Release)
+	return ga.Format(time.DateOnly)
+}
+
+// mustCurrent returns the current release of the calendar, and exits asking for the flag when
+// all the releases are GA.
+func mustCurrent(calendar reports.ReleaseCalendar, flag string) *reports.Release {
+	current := calendar.Current(time.Now())
+	if current == nil {
+		log.Fatalf("No release of the calendar has its GA today or later, set --%s or give a calendar with the next release with --calendar", flag)
+	}
+	return current
+}
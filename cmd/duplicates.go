@@ -0,0 +1,51 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"github.com/edcdavid/jira-helper/internal/reports"
+	"github.com/spf13/cobra"
+)
+
+var embeddingModel, embeddingsFile string
+var duplicateThreshold float64
+
+// duplicatesCmd represents the duplicates command
+var duplicatesCmd = &cobra.Command{
+	Use:   "duplicates",
+	Short: "Lists clusters of likely duplicate bugs in the bug status queries",
+	Long: `Computes embeddings of the summary and description of the bugs returned by the bug status
+queries with a local Ollama embedding model, and lists the groups of bugs more similar than
+the threshold. Embeddings are kept in a local file and only recomputed when the text changes.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		reports.GetDuplicatesReport(reports.DuplicatesOptions{
+			JiraURL:             jiraURL,
+			PersonalAccessToken: token,
+			FilterQuery:         issueFilter,
+			ReleaseCutoffDate:   releaseCutoffDate,
+			FromDate:            FromDate,
+			EmbeddingModel:      embeddingModel,
+			EmbeddingsFile:      embeddingsFile,
+			Threshold:           duplicateThreshold,
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(duplicatesCmd)
+	duplicatesCmd.Flags().StringVarP(&token, "token", "t", "", "The Personal Access Token from Jira")
+	duplicatesCmd.Flags().StringVarP(&jiraURL, "url", "u", "https://issues.redhat.com", "The Jira URL")
+	duplicatesCmd.Flags().StringVarP(&issueFilter, "issueFilter", "f", "",
+		"The Jira jql filter query (default: the bug status queries)")
+	duplicatesCmd.Flags().StringVarP(&releaseCutoffDate, "releaseDate", "r", "2025-05-12",
+		"The openshift release date (for example, 2025-05-12)")
+	duplicatesCmd.Flags().StringVarP(&FromDate, "fromDate", "d", "2023-05-15",
+		"The date from which to consider issues created")
+	duplicatesCmd.Flags().StringVarP(&embeddingModel, "embeddingModel", "e", "nomic-embed-text",
+		"The Ollama embedding model")
+	duplicatesCmd.Flags().StringVar(&embeddingsFile, "embeddingsFile", "jira-helper-embeddings.json",
+		"The local file where embeddings are kept between runs")
+	duplicatesCmd.Flags().Float64Var(&duplicateThreshold, "threshold", 0.9, //nolint:mnd
+		"The similarity (0 to 1) above which issues are reported as likely duplicates")
+}
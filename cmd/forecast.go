@@ -0,0 +1,72 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"log"
+	"time"
+
+	"github.com/edcdavid/jira-helper/internal/reports"
+	"github.com/spf13/cobra"
+)
+
+var forecastGA string
+var forecastWeeks, forecastSimulations int
+
+// forecastCmd represents the forecast command
+var forecastCmd = &cobra.Command{
+	Use:   "forecast",
+	Short: "Forecasts the completion date of a release with a Monte Carlo simulation",
+	Long: `Counts the child issues of the release epics resolved each week over the past weeks, from
+their changelog, and simulates the completion of the remaining ones by drawing the throughput
+of each future week from those past weeks. The P50, P85 and P95 completion dates are the dates
+by which 50%, 85% and 95% of the simulations are complete, and the gauge is the share of
+simulations complete by GA. Issues without children are counted themselves.
+
+Example:
+  jira-helper forecast --release 4.21 --ga 2026-02-24`,
+	Run: func(cmd *cobra.Command, args []string) {
+		calendar := loadReleaseCalendar()
+		release = currentRelease(cmd, calendar, release)
+		if forecastGA == "" {
+			if milestones := calendar.Find(release); milestones != nil {
+				if ga, ok := milestones.GA(); ok {
+					forecastGA = ga.Format(time.DateOnly)
+				}
+			}
+		}
+		if forecastGA == "" {
+			log.Fatalf("No GA date for release %s, set --ga or add the release to the calendar", release)
+		}
+		reports.GetForecastReport(reports.ForecastOptions{
+			JiraURL:             jiraURL,
+			PersonalAccessToken: token,
+			FilterQuery:         issueFilter,
+			Release:             release,
+			CustomerFacing:      customerFacing,
+			GA:                  forecastGA,
+			Weeks:               forecastWeeks,
+			Simulations:         forecastSimulations,
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(forecastCmd)
+	forecastCmd.Flags().StringVarP(&token, "token", "t", "", "The Personal Access Token from Jira")
+	forecastCmd.Flags().StringVarP(&jiraURL, "url", "u", "https://issues.redhat.com", "The Jira URL")
+	forecastCmd.Flags().StringVarP(&issueFilter, "issueFilter", "f", "", "The Jira jql filter query")
+	forecastCmd.Flags().StringVarP(&release, "release", "r", "4.20",
+		"The openshift release (for example, 4.20), the current release of the calendar when not set")
+	forecastCmd.Flags().StringVarP(&customerFacing, "customerFacing", "c", "both",
+		"yes for customer facing, not for not customer facing, and both for both")
+	forecastCmd.Flags().StringVar(&forecastGA, "ga", "",
+		"The GA date to forecast against (for example, 2026-02-24) (default: the GA of the release in the calendar)")
+	forecastCmd.Flags().IntVar(&forecastWeeks, "weeks", 12, //nolint:mnd
+		"Number of past weeks the weekly throughput is sampled from")
+	forecastCmd.Flags().IntVar(&forecastSimulations, "simulations", 10000, //nolint:mnd
+		"Number of simulated completions")
+	forecastCmd.Flags().StringVar(&releaseCalendar, "calendar", "",
+		"Release calendar YAML file replacing the embedded one, see internal/reports/calendar/releases.yml")
+}
@@ -0,0 +1,55 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"github.com/edcdavid/jira-helper/internal/reports"
+	"github.com/spf13/cobra"
+)
+
+var ganttFormat, ganttReleaseDate string
+
+// ganttCmd represents the gantt command
+var ganttCmd = &cobra.Command{
+	Use:   "gantt",
+	Short: "Draws the timeline of the release epics as a Gantt chart",
+	Long: `Draws one bar per issue of the report query, from its target start to its target end, or
+from its creation to its resolution when the target dates are missing. Open issues without
+target end run until today. Issues not done whose end has passed, or is after the release
+cutoff (the latest fixVersion release date, or --releaseDate), are late.
+
+The chart is written as a Mermaid gantt block for Markdown, or as an interactive echarts HTML
+page with the bars colored by the color field and lines for today and the release cutoff.
+
+Example:
+  jira-helper gantt --release 4.20 --format html > gantt.html`,
+	Run: func(cmd *cobra.Command, args []string) {
+		release = currentRelease(cmd, loadReleaseCalendar(), release)
+		reports.GetGanttReport(reports.GanttOptions{
+			JiraURL:             jiraURL,
+			PersonalAccessToken: token,
+			FilterQuery:         issueFilter,
+			Release:             release,
+			CustomerFacing:      customerFacing,
+			ReleaseDate:         ganttReleaseDate,
+			Format:              ganttFormat,
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(ganttCmd)
+	ganttCmd.Flags().StringVarP(&token, "token", "t", "", "The Personal Access Token from Jira")
+	ganttCmd.Flags().StringVarP(&jiraURL, "url", "u", "https://issues.redhat.com", "The Jira URL")
+	ganttCmd.Flags().StringVarP(&issueFilter, "issueFilter", "f", "", "The Jira jql filter query")
+	ganttCmd.Flags().StringVarP(&release, "release", "r", "4.20",
+		"The openshift release (for example, 4.20), the current release of the calendar when not set")
+	ganttCmd.Flags().StringVar(&releaseCalendar, "calendar", "",
+		"Release calendar YAML file replacing the embedded one, see internal/reports/calendar/releases.yml")
+	ganttCmd.Flags().StringVarP(&customerFacing, "customerFacing", "c", "both",
+		"yes for customer facing, not for not customer facing, and both for both")
+	ganttCmd.Flags().StringVar(&ganttReleaseDate, "releaseDate", "",
+		"The release cutoff (for example, 2025-05-12) (default: the latest fixVersion release date)")
+	ganttCmd.Flags().StringVar(&ganttFormat, "format", "mermaid", "Output format: mermaid or html")
+}
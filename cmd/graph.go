@@ -0,0 +1,69 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/edcdavid/jira-helper/internal/graph"
+	"github.com/edcdavid/jira-helper/internal/reports"
+	"github.com/spf13/cobra"
+)
+
+var graphOptions graph.Options
+
+// graphCmd represents the graph command
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Exports the links between the release issues as a Graphviz DOT or Mermaid graph",
+	Long: `Fetches the issues of the query and follows their links up to the given depth, then writes
+the graph as Graphviz DOT or as a Mermaid flowchart, with one cluster per project. Issues are
+filled with their color, done issues are dashed and issues in progress bold. The blocking
+cycles and the critical path, the longest chain of open issues blocking each other, are
+reported as text on stderr, or on stdout when the graph is written to a file.
+
+With --output, the graph is laid out and rendered to an SVG, PNG, JPEG or PDF file instead.
+
+Example:
+  jira-helper graph --release 4.20 --depth 2 --format dot | dot -Tsvg > release.svg
+  jira-helper graph --issueFilter "key = CNF-1234" --output cnf-1234.png`,
+	Run: func(cmd *cobra.Command, args []string) {
+		release = currentRelease(cmd, loadReleaseCalendar(), release)
+		graphOptions.Report = reports.ReportOptions{
+			JiraURL:             jiraURL,
+			PersonalAccessToken: token,
+			FilterQuery:         issueFilter,
+			Release:             release,
+			CustomerFacing:      customerFacing,
+		}
+		report := os.Stderr
+		if graphOptions.Output != "" {
+			report = os.Stdout
+		}
+		if err := graph.Run(context.Background(), &graphOptions, os.Stdout, report); err != nil {
+			log.Fatalf("Graph failed, err:%v", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(graphCmd)
+	graphCmd.Flags().StringVarP(&token, "token", "t", "", "The Personal Access Token from Jira")
+	graphCmd.Flags().StringVarP(&jiraURL, "url", "u", "https://issues.redhat.com", "The Jira URL")
+	graphCmd.Flags().StringVarP(&issueFilter, "issueFilter", "f", "", "The Jira jql filter query")
+	graphCmd.Flags().StringVarP(&release, "release", "r", "4.20",
+		"The openshift release (for example, 4.20), the current release of the calendar when not set")
+	graphCmd.Flags().StringVar(&releaseCalendar, "calendar", "",
+		"Release calendar YAML file replacing the embedded one, see internal/reports/calendar/releases.yml")
+	graphCmd.Flags().StringVarP(&customerFacing, "customerFacing", "c", "both",
+		"yes for customer facing, not for not customer facing, and both for both")
+	graphCmd.Flags().IntVar(&graphOptions.Depth, "depth", 1, "The number of links followed from the issues of the query")
+	graphCmd.Flags().StringSliceVar(&graphOptions.LinkTypes, "linkTypes", nil,
+		"The link types followed, for example Blocks,Depend (default: all)")
+	graphCmd.Flags().StringVar(&graphOptions.Format, "format", graph.FormatDOT, "The output format: dot or mermaid")
+	graphCmd.Flags().StringVarP(&graphOptions.Output, "output", "o", "",
+		"Render the graph to a .svg, .png, .jpg or .pdf file instead")
+}
@@ -0,0 +1,54 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"os"
+
+	"github.com/edcdavid/jira-helper/internal/reports"
+	"github.com/spf13/cobra"
+)
+
+var hygieneRules, hygieneFormat string
+var hygieneFail bool
+
+// hygieneCmd represents the hygiene command
+var hygieneCmd = &cobra.Command{
+	Use:   "hygiene",
+	Short: "Checks the release issues against data hygiene rules",
+	Long: `Runs the data hygiene rules (epic with no color, stale status summary, closed but red, ...)
+over the issues of the report query, and lists the violations per rule.
+Rules are defined in YAML, see internal/reports/rules/hygiene.yml for the default ones.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		release = currentRelease(cmd, loadReleaseCalendar(), release)
+		violations := reports.GetHygieneReport(reports.HygieneOptions{
+			JiraURL:             jiraURL,
+			PersonalAccessToken: token,
+			FilterQuery:         issueFilter,
+			Release:             release,
+			CustomerFacing:      customerFacing,
+			RulesFile:           hygieneRules,
+			Format:              hygieneFormat,
+		})
+		if hygieneFail && violations > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(hygieneCmd)
+	hygieneCmd.Flags().StringVarP(&token, "token", "t", "", "The Personal Access Token from Jira")
+	hygieneCmd.Flags().StringVarP(&jiraURL, "url", "u", "https://issues.redhat.com", "The Jira URL")
+	hygieneCmd.Flags().StringVarP(&issueFilter, "issueFilter", "f", "", "The Jira jql filter query")
+	hygieneCmd.Flags().StringVarP(&release, "release", "r", "4.20",
+		"The openshift release (for example, 4.20), the current release of the calendar when not set")
+	hygieneCmd.Flags().StringVar(&releaseCalendar, "calendar", "",
+		"Release calendar YAML file replacing the embedded one, see internal/reports/calendar/releases.yml")
+	hygieneCmd.Flags().StringVarP(&customerFacing, "customerFacing", "c", "both",
+		"yes for customer facing, not for not customer facing, and both for both")
+	hygieneCmd.Flags().StringVar(&hygieneRules, "rules", "", "Rules YAML file replacing the embedded rules")
+	hygieneCmd.Flags().StringVar(&hygieneFormat, "format", "markdown", "Output format: markdown or json")
+	hygieneCmd.Flags().BoolVar(&hygieneFail, "failOnViolations", false, "Exit with an error when a rule is violated")
+}
@@ -0,0 +1,19 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// jqlCmd represents the jql command
+var jqlCmd = &cobra.Command{
+	Use:   "jql",
+	Short: "Helpers to write JQL queries",
+	Long:  ``,
+}
+
+func init() {
+	rootCmd.AddCommand(jqlCmd)
+}
@@ -0,0 +1,74 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/edcdavid/jira-helper/internal/jirahelper"
+	"github.com/edcdavid/jira-helper/internal/jqlassist"
+	"github.com/edcdavid/jira-helper/internal/reports"
+	"github.com/spf13/cobra"
+)
+
+var jqlPrompt string
+var jqlMaxNames, jqlRetries int
+var jqlRun bool
+
+// jqlAskCmd represents the jql ask command
+var jqlAskCmd = &cobra.Command{
+	Use:   "ask <question>",
+	Short: "Translates a question into JQL with a local model",
+	Long: `Proposes a JQL query answering the question with a local Ollama model, using the field,
+project and status names of the Jira server as context. The query is validated by the
+server, then printed, or run with the report pipeline when --run is set.
+
+Example:
+  jira-helper jql ask "open telco bugs in networking created this month" -m llama3`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := jirahelper.NewClient(jiraURL, token)
+		if err != nil {
+			log.Fatal(err)
+		}
+		jql, validationErrors, err := jqlassist.Ask(context.Background(), client, ollamaModel, jqlPrompt,
+			strings.Join(args, " "), jqlMaxNames, jqlRetries)
+		if err != nil {
+			log.Fatalf("Cannot translate the question to JQL, err:%v", err)
+		}
+		if len(validationErrors) > 0 {
+			fmt.Fprintf(os.Stderr, "The proposed JQL is not valid:\n%s\n\n%s\n", jql, strings.Join(validationErrors, "\n"))
+			os.Exit(1)
+		}
+
+		if !jqlRun {
+			fmt.Println(jql)
+			return
+		}
+		fmt.Fprintln(os.Stderr, "Running JQL:", jql)
+		reports.GetMarkdownReport(reports.ReportOptions{
+			JiraURL:             jiraURL,
+			PersonalAccessToken: token,
+			FilterQuery:         jql,
+		})
+	},
+}
+
+func init() {
+	jqlCmd.AddCommand(jqlAskCmd)
+	jqlAskCmd.Flags().StringVarP(&token, "token", "t", "", "The Personal Access Token from Jira")
+	jqlAskCmd.Flags().StringVarP(&jiraURL, "url", "u", "https://issues.redhat.com", "The Jira URL")
+	jqlAskCmd.Flags().StringVarP(&ollamaModel, "ollamaModel", "m", "", "The Ollama model proposing the JQL")
+	jqlAskCmd.Flags().StringVarP(&jqlPrompt, "prompt", "p", "", "Prompt template file overriding the embedded JQL prompt")
+	jqlAskCmd.Flags().IntVar(&jqlMaxNames, "maxNames", 200, //nolint:mnd
+		"Maximum number of field, project and status names each given to the model")
+	jqlAskCmd.Flags().IntVar(&jqlRetries, "retries", 1,
+		"Number of times a query rejected by the server is sent back to the model to be fixed")
+	jqlAskCmd.Flags().BoolVar(&jqlRun, "run", false, "Run the query with the report pipeline instead of printing it")
+	_ = jqlAskCmd.MarkFlagRequired("ollamaModel")
+}
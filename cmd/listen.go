@@ -0,0 +1,104 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/edcdavid/jira-helper/internal/issuestore"
+	"github.com/edcdavid/jira-helper/internal/jirahelper"
+	"github.com/edcdavid/jira-helper/internal/reports"
+	"github.com/spf13/cobra"
+)
+
+var listenStore, listenSecret string
+var listenVerify, listenReplay bool
+var listenOptions issuestore.ListenOptions
+
+// listenCmd represents the listen command
+var listenCmd = &cobra.Command{
+	Use:   "listen",
+	Short: "Keeps a local store of the report issues up to date from Jira webhooks",
+	Long: `Receives the jira:issue_created, jira:issue_updated and jira:issue_deleted webhooks and
+applies them to an on-disk store of the issues of the report query, which is synced with the
+full query at startup and every --resync interval. Render the report from the store with
+'report --from-store'.
+
+The shared secret is read from --secret or JIRA_HELPER_WEBHOOK_SECRET. It must be sent as the
+secret query parameter of the webhook URL, or as the HMAC signature key on Jira Cloud.
+
+With --replay, the recorded webhook payload files given as arguments are applied to the store
+and the command exits, for example:
+  jira-helper listen --verify=false --replay internal/issuestore/testdata/webhooks/*.json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		release = currentRelease(cmd, loadReleaseCalendar(), release)
+		if len(args) > 0 && !listenReplay {
+			log.Fatal("Payload files can only be given with --replay")
+		}
+		query, err := reports.ReportQuery(&reports.ReportOptions{
+			FilterQuery:    issueFilter,
+			Release:        release,
+			CustomerFacing: customerFacing,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		store, err := issuestore.Load(listenStore, query)
+		if err != nil {
+			log.Fatalf("Cannot load the issue store %s, err:%v", listenStore, err)
+		}
+		client, err := jirahelper.NewClient(jiraURL, token)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if listenSecret == "" {
+			listenSecret = os.Getenv("JIRA_HELPER_WEBHOOK_SECRET")
+		}
+		handler := &issuestore.Handler{Store: store, StorePath: listenStore, Secret: listenSecret}
+		if listenVerify {
+			handler.Client = client
+		}
+
+		if listenReplay {
+			if err := issuestore.Replay(context.Background(), handler, args, os.Stdout); err != nil {
+				log.Fatalf("Replay failed, err:%v", err)
+			}
+			return
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if err := issuestore.Listen(ctx, client, handler, &listenOptions); err != nil {
+			log.Fatalf("Listen failed, err:%v", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(listenCmd)
+	listenCmd.Flags().StringVarP(&token, "token", "t", "", "The Personal Access Token from Jira")
+	listenCmd.Flags().StringVarP(&jiraURL, "url", "u", "https://issues.redhat.com", "The Jira URL")
+	listenCmd.Flags().StringVarP(&issueFilter, "issueFilter", "f", "", "The Jira jql filter query")
+	listenCmd.Flags().StringVarP(&release, "release", "r", "4.20",
+		"The openshift release (for example, 4.20), the current release of the calendar when not set")
+	listenCmd.Flags().StringVar(&releaseCalendar, "calendar", "",
+		"Release calendar YAML file replacing the embedded one, see internal/reports/calendar/releases.yml")
+	listenCmd.Flags().StringVarP(&customerFacing, "customerFacing", "c", "both",
+		"yes for customer facing, not for not customer facing, and both for both")
+	listenCmd.Flags().StringVarP(&listenOptions.Listen, "listen", "l", ":8081", "The address to listen on")
+	listenCmd.Flags().StringVar(&listenOptions.Path, "path", "/webhook", "The URL path of the webhook endpoint")
+	listenCmd.Flags().DurationVar(&listenOptions.Resync, "resync", 24*time.Hour, //nolint:mnd
+		"Interval of the full query catching missed webhooks, 0 to disable")
+	listenCmd.Flags().StringVar(&listenStore, "store", "jira-helper-store.json", "The issue store file")
+	listenCmd.Flags().StringVar(&listenSecret, "secret", "", "The shared secret of the webhook")
+	listenCmd.Flags().BoolVar(&listenVerify, "verify", true,
+		"Fetch created and updated issues from Jira to check they match the query, instead of storing the payload")
+	listenCmd.Flags().BoolVar(&listenReplay, "replay", false,
+		"Apply the recorded webhook payload files given as arguments to the store and exit")
+}
@@ -0,0 +1,77 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/edcdavid/jira-helper/internal/jirahelper"
+	"github.com/edcdavid/jira-helper/internal/nudge"
+	"github.com/edcdavid/jira-helper/internal/reports"
+	"github.com/edcdavid/jira-helper/internal/timehelper"
+	"github.com/spf13/cobra"
+)
+
+var nudgeThreshold, nudgeWindow string
+var nudgeOptions nudge.Options
+
+// nudgeCmd represents the nudge command
+var nudgeCmd = &cobra.Command{
+	Use:   "nudge",
+	Short: "Reminds assignees to update missing or stale status summaries",
+	Long: `Posts a comment mentioning the assignee on the open issues of the report query whose status
+summary is missing or older than the threshold. An issue is not reminded again within the
+window, tracked with a local ledger and a marker in the comment.
+Nothing is posted unless --dry-run=false is given.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		release = currentRelease(cmd, loadReleaseCalendar(), release)
+		var err error
+		nudgeOptions.Threshold, err = timehelper.ParseDuration(nudgeThreshold)
+		if err != nil {
+			log.Fatalf("Invalid --threshold value %q, err:%v", nudgeThreshold, err)
+		}
+		nudgeOptions.Window, err = timehelper.ParseDuration(nudgeWindow)
+		if err != nil {
+			log.Fatalf("Invalid --window value %q, err:%v", nudgeWindow, err)
+		}
+		nudgeOptions.JQL = issueFilter
+		if nudgeOptions.JQL == "" {
+			nudgeOptions.JQL = reports.GetFilterFromRelease(release, customerFacing)
+		}
+
+		client, err := jirahelper.NewClient(jiraURL, token)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := nudge.Run(context.Background(), client, &nudgeOptions, os.Stdout); err != nil {
+			log.Fatalf("Nudge failed, err:%v", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(nudgeCmd)
+	nudgeCmd.Flags().StringVarP(&token, "token", "t", "", "The Personal Access Token from Jira")
+	nudgeCmd.Flags().StringVarP(&jiraURL, "url", "u", "https://issues.redhat.com", "The Jira URL")
+	nudgeCmd.Flags().StringVarP(&issueFilter, "issueFilter", "f", "", "The Jira jql filter query")
+	nudgeCmd.Flags().StringVarP(&release, "release", "r", "4.20",
+		"The openshift release (for example, 4.20), the current release of the calendar when not set")
+	nudgeCmd.Flags().StringVar(&releaseCalendar, "calendar", "",
+		"Release calendar YAML file replacing the embedded one, see internal/reports/calendar/releases.yml")
+	nudgeCmd.Flags().StringVarP(&customerFacing, "customerFacing", "c", "both",
+		"yes for customer facing, not for not customer facing, and both for both")
+	nudgeCmd.Flags().StringVar(&nudgeThreshold, "threshold", "14d", "Age from which a status summary is stale")
+	nudgeCmd.Flags().StringVar(&nudgeWindow, "window", "7d", "Minimum time between two reminders on the same issue")
+	nudgeCmd.Flags().StringVar(&nudgeOptions.Ledger, "ledger", "jira-helper-nudge.json",
+		"The local file recording the reminders posted")
+	nudgeCmd.Flags().StringVar(&nudgeOptions.TemplateFile, "template", "",
+		"Comment template file replacing the embedded one")
+	nudgeCmd.Flags().DurationVar(&nudgeOptions.Interval, "interval", time.Second, "Minimum time between two comments")
+	nudgeCmd.Flags().IntVar(&nudgeOptions.Retries, "retries", 3, //nolint:mnd
+		"Retries of a comment rejected by rate limiting or a server error")
+	nudgeCmd.Flags().BoolVar(&nudgeOptions.DryRun, "dry-run", true, "Only show the comments that would be posted")
+}
@@ -4,12 +4,19 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"log"
+	"time"
+
 	"github.com/edcdavid/jira-helper/internal/reports"
+	"github.com/edcdavid/jira-helper/internal/timehelper"
 	"github.com/spf13/cobra"
 )
 
-var issueFilter, token, jiraURL, release, customerFacing, ollamaModel string
-var showOriginalStatus bool
+var issueFilter, token, jiraURL, release, customerFacing, ollamaModel, formatPrompt, summaryPrompt string
+var showOriginalStatus, summary, suggestColor, suggestWithAI, rollup bool
+var suggestPrompt, staleAfter, fromStore string
+var suggestStaleDays int
+var emailOptions reports.EmailOptions
 
 // reportCmd represents the report command
 var reportCmd = &cobra.Command{
@@ -17,7 +24,41 @@ var reportCmd = &cobra.Command{
 	Short: "Create a report listing red and yellow issues",
 	Long:  ``,
 	Run: func(cmd *cobra.Command, args []string) {
-		reports.GetMarkdownReport(jiraURL, token, issueFilter, release, customerFacing, ollamaModel, showOriginalStatus)
+		var staleAfterDuration time.Duration
+		if staleAfter != "" {
+			var err error
+			staleAfterDuration, err = timehelper.ParseDuration(staleAfter)
+			if err != nil {
+				log.Fatalf("Invalid --stale-after value %q, err:%v", staleAfter, err)
+			}
+		}
+		calendar := loadReleaseCalendar()
+		release = currentRelease(cmd, calendar, release)
+		opts := reports.ReportOptions{
+			JiraURL:             jiraURL,
+			PersonalAccessToken: token,
+			FilterQuery:         issueFilter,
+			Release:             release,
+			CustomerFacing:      customerFacing,
+			OllamaModel:         ollamaModel,
+			ShowOriginalStatus:  showOriginalStatus,
+			FormatPrompt:        formatPrompt,
+			Summary:             summary,
+			SummaryPrompt:       summaryPrompt,
+			SuggestColor:        suggestColor,
+			SuggestStaleDays:    suggestStaleDays,
+			SuggestWithAI:       suggestWithAI,
+			SuggestPrompt:       suggestPrompt,
+			StaleAfter:          staleAfterDuration,
+			FromStore:           fromStore,
+			Rollup:              rollup,
+			Milestones:          calendar.Find(release),
+		}
+		if emailOptions.EMLFile != "" || emailOptions.SMTP.Addr != "" {
+			reports.GetEmailReport(opts, emailOptions)
+			return
+		}
+		reports.GetMarkdownReport(opts)
 	},
 }
 
@@ -26,10 +67,43 @@ func init() {
 	reportCmd.Flags().StringVarP(&token, "token", "t", "", "The Personal Access Token from Jira")
 	reportCmd.Flags().StringVarP(&jiraURL, "url", "u", "https://issues.redhat.com", "The Jira URL")
 	reportCmd.Flags().StringVarP(&issueFilter, "issueFilter", "f", "", "The Jira jql filter query")
-	reportCmd.Flags().StringVarP(&release, "release", "r", "4.20", "The openshift release (for example, 4.20)")
+	reportCmd.Flags().StringVarP(&release, "release", "r", "4.20",
+		"The openshift release (for example, 4.20), the current release of the calendar when not set")
+	reportCmd.Flags().StringVar(&releaseCalendar, "calendar", "",
+		"Release calendar YAML file replacing the embedded one, see internal/reports/calendar/releases.yml")
 	reportCmd.Flags().StringVarP(&customerFacing, "customerFacing", "c", "both",
 		"yes for customer facing, not for not customer facing, and both for both")
 	reportCmd.Flags().StringVarP(&ollamaModel, "ollamaModel", "m", "",
 		"Use specified model in Ollama to clean suummary status")
 	reportCmd.Flags().BoolVarP(&showOriginalStatus, "originalStatus", "o", false, "Add the original status summary in code blocks")
+	reportCmd.Flags().StringVar(&formatPrompt, "formatPrompt", "",
+		"Prompt template file overriding the embedded status summary cleanup prompt")
+	reportCmd.Flags().BoolVarP(&summary, "summary", "s", false,
+		"Add an executive summary of the red and yellow issues generated with the Ollama model")
+	reportCmd.Flags().StringVar(&summaryPrompt, "summaryPrompt", "",
+		"Prompt template file overriding the embedded executive summary prompt")
+	reportCmd.Flags().BoolVar(&suggestColor, "suggestColor", false,
+		"Suggest a health color, with a reason, for the issues without color")
+	reportCmd.Flags().IntVar(&suggestStaleDays, "suggestStaleDays", 14, //nolint:mnd
+		"Number of days without update after which a suggested color is at least yellow")
+	reportCmd.Flags().BoolVar(&suggestWithAI, "suggestWithAI", false,
+		"Refine the suggested colors with the Ollama model")
+	reportCmd.Flags().StringVar(&suggestPrompt, "suggestPrompt", "",
+		"Prompt template file overriding the embedded suggest color prompt")
+	reportCmd.Flags().StringVar(&staleAfter, "stale-after", "",
+		"Move red and yellow issues whose latest status is older (for example, 14d) to a stale section")
+	reportCmd.Flags().StringVar(&fromStore, "from-store", "",
+		"Render from the issue store file maintained by the listen command instead of querying Jira")
+	reportCmd.Flags().BoolVar(&rollup, "rollup", false,
+		"Show the completion of each epic from its child issues, and a release completion chart")
+	reportCmd.Flags().StringVar(&emailOptions.EMLFile, "eml", "",
+		"Write the report as an email with inline charts to this .eml file instead of printing markdown")
+	reportCmd.Flags().StringVar(&emailOptions.SMTP.Addr, "smtp", "",
+		"Send the report as an email through this SMTP server (host:port)")
+	reportCmd.Flags().StringVar(&emailOptions.SMTP.User, "smtpUser", "",
+		"The SMTP user, the password is read from JIRA_HELPER_SMTP_PASSWORD")
+	reportCmd.Flags().StringVar(&emailOptions.Message.From, "mailFrom", "", "The sender of the email")
+	reportCmd.Flags().StringSliceVar(&emailOptions.Message.To, "mailTo", nil, "The recipients of the email")
+	reportCmd.Flags().StringVar(&emailOptions.Message.Subject, "subject", "",
+		"The subject of the email (default: Release <release> status - <date>)")
 }
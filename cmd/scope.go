@@ -0,0 +1,74 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"log"
+	"time"
+
+	"github.com/edcdavid/jira-helper/internal/reports"
+	"github.com/spf13/cobra"
+)
+
+var scopeFixVersion, scopeIssueType, scopeSince string
+var scopeProjects []string
+
+// scopeCmd represents the scope command
+var scopeCmd = &cobra.Command{
+	Use:   "scope",
+	Short: "Lists the issues added to or removed from a release since its feature freeze",
+	Long: `Reads the fix version changes of the changelog of the issues of the projects, and lists the
+issues added to the fix version, removed from it or moved to another release since a date,
+with who made the change and when. The chart shows the issues in the fix version at the end of
+each week, and those of them done.
+
+The fix version is openshift-<release> unless --fixVersion is set, and the date is the feature
+freeze of the release in the calendar unless --since is set.
+
+Example:
+  jira-helper scope --release 4.20`,
+	Run: func(cmd *cobra.Command, args []string) {
+		calendar := loadReleaseCalendar()
+		release = currentRelease(cmd, calendar, release)
+		if scopeFixVersion == "" {
+			scopeFixVersion = "openshift-" + release
+		}
+		if scopeSince == "" {
+			if milestones := calendar.Find(release); milestones != nil {
+				if freeze, ok := milestones.Milestone(reports.FeatureFreezeMilestone); ok {
+					scopeSince = freeze.Format(time.DateOnly)
+				}
+			}
+		}
+		if scopeSince == "" {
+			log.Fatalf("No feature freeze date for release %s, set --since or add the release to the calendar", release)
+		}
+		reports.GetScopeReport(reports.ScopeOptions{
+			JiraURL:             jiraURL,
+			PersonalAccessToken: token,
+			Projects:            scopeProjects,
+			IssueType:           scopeIssueType,
+			FixVersion:          scopeFixVersion,
+			Since:               scopeSince,
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(scopeCmd)
+	scopeCmd.Flags().StringVarP(&token, "token", "t", "", "The Personal Access Token from Jira")
+	scopeCmd.Flags().StringVarP(&jiraURL, "url", "u", "https://issues.redhat.com", "The Jira URL")
+	scopeCmd.Flags().StringVarP(&release, "release", "r", "4.20",
+		"The openshift release (for example, 4.20), the current release of the calendar when not set")
+	scopeCmd.Flags().StringVar(&scopeFixVersion, "fixVersion", "",
+		"The fix version whose scope changes are listed (default: openshift-<release>)")
+	scopeCmd.Flags().StringSliceVar(&scopeProjects, "projects",
+		[]string{"Cloud-native Network Functions", "OpenShift Edge Enablement", "KNI QE - System Test"},
+		"The projects whose issues are followed")
+	scopeCmd.Flags().StringVar(&scopeIssueType, "issueType", "Epic", "The type of the issues followed")
+	scopeCmd.Flags().StringVar(&scopeSince, "since", "",
+		"The date from which the changes are listed (for example, 2025-08-01) (default: the feature freeze of the release in the calendar)")
+	scopeCmd.Flags().StringVar(&releaseCalendar, "calendar", "",
+		"Release calendar YAML file replacing the embedded one, see internal/reports/calendar/releases.yml")
+}
@@ -0,0 +1,81 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/edcdavid/jira-helper/internal/server"
+	"github.com/edcdavid/jira-helper/internal/timehelper"
+	"github.com/spf13/cobra"
+)
+
+var serveStaleAfter string
+var serveOptions server.Options
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serves the reports over HTTP",
+	Long: `Serves the reports as HTML pages and JSON:
+  /report?release=4.20&customerFacing=yes
+  /bugstatus?releaseDate=2025-05-12&fromDate=2023-05-15
+  /api/report.json?release=4.20&customerFacing=yes
+  /metrics
+Reports are fetched from Jira on first request and refreshed in the background, so pages are
+served from memory. The server shuts down gracefully on SIGINT or SIGTERM.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if serveStaleAfter != "" {
+			var err error
+			serveOptions.StaleAfter, err = timehelper.ParseDuration(serveStaleAfter)
+			if err != nil {
+				log.Fatalf("Invalid --stale-after value %q, err:%v", serveStaleAfter, err)
+			}
+		}
+		serveOptions.Calendar = loadReleaseCalendar()
+		serveOptions.Release = currentRelease(cmd, serveOptions.Calendar, serveOptions.Release)
+		serveOptions.ReleaseDate = currentReleaseDate(cmd, serveOptions.Calendar, serveOptions.ReleaseDate)
+		if len(serveOptions.MetricsReleases) == 0 {
+			serveOptions.MetricsReleases = []string{serveOptions.Release}
+		}
+		serveOptions.JiraURL = jiraURL
+		serveOptions.PersonalAccessToken = token
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if err := server.Run(ctx, &serveOptions); err != nil {
+			log.Fatalf("Server failed, err:%v", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().StringVarP(&token, "token", "t", "", "The Personal Access Token from Jira")
+	serveCmd.Flags().StringVarP(&jiraURL, "url", "u", "https://issues.redhat.com", "The Jira URL")
+	serveCmd.Flags().StringVarP(&serveOptions.Listen, "listen", "l", ":8080", "The address to listen on")
+	serveCmd.Flags().DurationVar(&serveOptions.Refresh, "refresh", 15*time.Minute, //nolint:mnd
+		"Interval at which the cached reports are refreshed from Jira, 0 to disable")
+	serveCmd.Flags().StringVarP(&serveOptions.Release, "release", "r", "4.20",
+		"The default openshift release (for example, 4.20), the current release of the calendar when not set")
+	serveCmd.Flags().StringVarP(&serveOptions.CustomerFacing, "customerFacing", "c", "both",
+		"The default customerFacing: yes, no or both")
+	serveCmd.Flags().StringVar(&serveOptions.ReleaseDate, "releaseDate", "2025-05-12",
+		"The default openshift release date of /bugstatus, the GA of the current release of the calendar when not set")
+	serveCmd.Flags().StringVar(&releaseCalendar, "calendar", "",
+		"Release calendar YAML file replacing the embedded one, see internal/reports/calendar/releases.yml")
+	serveCmd.Flags().StringVar(&serveOptions.FromDate, "fromDate", "2023-05-15",
+		"The default date from which /bugstatus considers issues created")
+	serveCmd.Flags().StringVar(&serveStaleAfter, "stale-after", "",
+		"Move red and yellow issues whose latest status is older (for example, 14d) to a stale section")
+	serveCmd.Flags().StringSliceVar(&serveOptions.MetricsReleases, "metrics-releases", nil,
+		"Releases exported on /metrics, built at startup and refreshed (default: --release)")
+	serveCmd.Flags().StringSliceVar(&serveOptions.MetricsCustomerFacing, "metrics-customerFacing", []string{"yes", "no"},
+		"customerFacing values exported on /metrics for each release")
+}
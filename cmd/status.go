@@ -0,0 +1,19 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// statusCmd represents the status command
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Updates the color and status summary of issues",
+	Long:  ``,
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+}
@@ -0,0 +1,55 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/edcdavid/jira-helper/internal/jirahelper"
+	"github.com/edcdavid/jira-helper/internal/statusupdate"
+	"github.com/spf13/cobra"
+)
+
+var statusColor, statusMessage string
+var dryRun bool
+
+// statusSetCmd represents the status set command
+var statusSetCmd = &cobra.Command{
+	Use:   "set <issue key>",
+	Short: "Adds a status entry and sets the color of an issue",
+	Long: `Prepends an entry dated today to the status summary of the issue, in the format of the
+existing entries, and updates its color. Without --message, $EDITOR is opened with the
+current text.
+
+Example:
+  jira-helper status set EPIC-123 --color Yellow --message "Waiting on QE, 1 week late"`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := jirahelper.NewClient(jiraURL, token)
+		if err != nil {
+			log.Fatal(err)
+		}
+		err = statusupdate.Set(context.Background(), client, &statusupdate.Options{
+			Key:     args[0],
+			Color:   statusColor,
+			Message: statusMessage,
+			Editor:  os.Getenv("EDITOR"),
+			DryRun:  dryRun,
+		}, os.Stdout)
+		if err != nil {
+			log.Fatalf("Cannot update %s, err:%v", args[0], err)
+		}
+	},
+}
+
+func init() {
+	statusCmd.AddCommand(statusSetCmd)
+	statusSetCmd.Flags().StringVarP(&token, "token", "t", "", "The Personal Access Token from Jira")
+	statusSetCmd.Flags().StringVarP(&jiraURL, "url", "u", "https://issues.redhat.com", "The Jira URL")
+	statusSetCmd.Flags().StringVar(&statusColor, "color", "", "The new color: Green, Yellow or Red")
+	statusSetCmd.Flags().StringVar(&statusMessage, "message", "", "The new status entry (default: open $EDITOR)")
+	statusSetCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the changes without updating the issue")
+}
@@ -0,0 +1,84 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/edcdavid/jira-helper/internal/reports"
+	"github.com/edcdavid/jira-helper/internal/watch"
+	"github.com/spf13/cobra"
+)
+
+var watchSlack, watchGoogleChat, watchTeams, watchGeneric []string
+var watchOptions watch.Options
+
+// watchCmd represents the watch command
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Announces issues turning red, changing status or leaving the release to chat webhooks",
+	Long: `Checks the report query every interval and posts the issues that turned Red, changed status
+or left the query to Slack, Google Chat or Microsoft Teams incoming webhooks, or to a generic
+JSON webhook. The last seen values and the changes not delivered yet are kept in the state
+file, so each change is announced once, even after a restart. The first check of a new state
+file only records the issues.
+
+Example:
+  jira-helper watch --release 4.20 --interval 15m --slack https://hooks.slack.com/services/...`,
+	Run: func(cmd *cobra.Command, args []string) {
+		release = currentRelease(cmd, loadReleaseCalendar(), release)
+		for kind, urls := range map[string][]string{
+			watch.Slack: watchSlack, watch.GoogleChat: watchGoogleChat, watch.Teams: watchTeams, watch.Generic: watchGeneric,
+		} {
+			for _, url := range urls {
+				target, err := watch.NewTarget(kind, url)
+				if err != nil {
+					log.Fatal(err)
+				}
+				watchOptions.Targets = append(watchOptions.Targets, target)
+			}
+		}
+		watchOptions.Report = reports.ReportOptions{
+			JiraURL:             jiraURL,
+			PersonalAccessToken: token,
+			FilterQuery:         issueFilter,
+			Release:             release,
+			CustomerFacing:      customerFacing,
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if err := watch.Run(ctx, &watchOptions, os.Stdout); err != nil {
+			log.Fatalf("Watch failed, err:%v", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+	watchCmd.Flags().StringVarP(&token, "token", "t", "", "The Personal Access Token from Jira")
+	watchCmd.Flags().StringVarP(&jiraURL, "url", "u", "https://issues.redhat.com", "The Jira URL")
+	watchCmd.Flags().StringVarP(&issueFilter, "issueFilter", "f", "", "The Jira jql filter query")
+	watchCmd.Flags().StringVarP(&release, "release", "r", "4.20",
+		"The openshift release (for example, 4.20), the current release of the calendar when not set")
+	watchCmd.Flags().StringVar(&releaseCalendar, "calendar", "",
+		"Release calendar YAML file replacing the embedded one, see internal/reports/calendar/releases.yml")
+	watchCmd.Flags().StringVarP(&customerFacing, "customerFacing", "c", "both",
+		"yes for customer facing, not for not customer facing, and both for both")
+	watchCmd.Flags().DurationVar(&watchOptions.Interval, "interval", 15*time.Minute, //nolint:mnd
+		"Interval between two checks")
+	watchCmd.Flags().BoolVar(&watchOptions.Once, "once", false, "Check once and exit, for example from cron")
+	watchCmd.Flags().StringVar(&watchOptions.StateFile, "state", "jira-helper-watch.json",
+		"The state file of the last seen values")
+	watchCmd.Flags().StringArrayVar(&watchSlack, "slack", nil, "Slack incoming webhook URL, can be repeated")
+	watchCmd.Flags().StringArrayVar(&watchGoogleChat, "googlechat", nil, "Google Chat incoming webhook URL, can be repeated")
+	watchCmd.Flags().StringArrayVar(&watchTeams, "teams", nil, "Microsoft Teams incoming webhook URL, can be repeated")
+	watchCmd.Flags().StringArrayVar(&watchGeneric, "webhook", nil,
+		"Generic webhook URL receiving the changes as JSON, can be repeated")
+}
@@ -0,0 +1,140 @@
+package aieval
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/edcdavid/jira-helper/internal/ollamahelper"
+	"github.com/edcdavid/jira-helper/internal/prompts"
+	"github.com/edcdavid/jira-helper/internal/statussummary"
+	"gopkg.in/yaml.v3"
+)
+
+const percent = 100
+
+// FixtureSet is a list of status summaries with the output expected from the format status prompt.
+type FixtureSet struct {
+	// Year is passed to the prompt so fixtures do not depend on the current date.
+	Year  int       `yaml:"year"`
+	Cases []Fixture `yaml:"cases"`
+}
+
+type Fixture struct {
+	Name     string `yaml:"name"`
+	Input    string `yaml:"input"`
+	Expected string `yaml:"expected"`
+}
+
+// Result is the outcome of one fixture.
+type Result struct {
+	Name       string
+	Output     string
+	ExactMatch bool
+	Similarity float64
+}
+
+//go:embed fixtures/format_status.yml
+var defaultFixturesYAML []byte
+
+// LoadFixtures reads a fixture set from path, or the embedded example set when path is empty.
+func LoadFixtures(path string) (*FixtureSet, error) {
+	data := defaultFixturesYAML
+	if path != "" {
+		var err error
+		data, err = os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+	set := &FixtureSet{}
+	if err := yaml.Unmarshal(data, set); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// Run sends every fixture through the format status prompt (or the template at promptOverride)
+// with the given model, and scores the answers against the expected outputs. Only the Ollama
+// server is contacted, so a local server makes the evaluation fully offline.
+func Run(ctx context.Context, set *FixtureSet, ollamaModel, promptOverride string) ([]Result, error) {
+	systemPrompt, err := prompts.Render(prompts.FormatStatus, promptOverride, prompts.FormatStatusData{Year: set.Year})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, 0, len(set.Cases))
+	for _, fixture := range set.Cases {
+		output, err := ollamahelper.Chat(ctx, ollamaModel, systemPrompt, statussummary.Normalize(fixture.Input))
+		if err != nil {
+			return nil, fmt.Errorf("fixture %s: %w", fixture.Name, err)
+		}
+		results = append(results, Result{
+			Name:       fixture.Name,
+			Output:     output,
+			ExactMatch: strings.TrimSpace(output) == strings.TrimSpace(fixture.Expected),
+			Similarity: Similarity(output, fixture.Expected),
+		})
+	}
+	return results, nil
+}
+
+// Similarity is 1 minus the word level edit distance divided by the length of the longest
+// text, so 1 is identical and 0 has nothing in common.
+func Similarity(a, b string) float64 {
+	wordsA, wordsB := strings.Fields(a), strings.Fields(b)
+	longest := max(len(wordsA), len(wordsB))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(editDistance(wordsA, wordsB))/float64(longest)
+}
+
+func editDistance(a, b []string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// WriteReport prints the per fixture scores and the totals as Markdown, and returns the mean
+// similarity.
+func WriteReport(w io.Writer, ollamaModel, prompt string, results []Result) float64 {
+	fmt.Fprintf(w, "Model: %s, prompt: %s\n\n", ollamaModel, prompt)
+	fmt.Fprintln(w, "| Fixture | Exact match | Similarity |")
+	fmt.Fprintln(w, "|---|---|---|")
+
+	exact := 0
+	similarity := 0.0
+	for _, result := range results {
+		if result.ExactMatch {
+			exact++
+		}
+		similarity += result.Similarity
+		fmt.Fprintf(w, "| %s | %t | %.1f%% |\n", result.Name, result.ExactMatch, result.Similarity*percent)
+	}
+	if len(results) == 0 {
+		return 0
+	}
+
+	mean := similarity / float64(len(results))
+	fmt.Fprintf(w, "\nExact match: %d/%d (%.1f%%), mean similarity: %.1f%%\n",
+		exact, len(results), float64(exact)/float64(len(results))*percent, mean*percent)
+	return mean
+}
@@ -0,0 +1,87 @@
+package bulk
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"time"
+)
+
+const (
+	operationMoveVersion = "move-version"
+	operationUndo        = "undo-move-version"
+
+	statusApplied = "applied"
+	statusFailed  = "failed"
+)
+
+// AuditEntry is one line of the JSON lines audit log. Every change attempt is recorded, so
+// the log can be replayed in reverse by undo.
+type AuditEntry struct {
+	Time      time.Time `json:"time"`
+	RunID     string    `json:"runId"`
+	Operation string    `json:"operation"`
+	Key       string    `json:"key"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	// ToPresent tells that To was already a fixVersion of the issue before the change, so an
+	// undo keeps it.
+	ToPresent bool   `json:"toPresent,omitempty"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+	// UndoOf is the run id reverted by an undo entry.
+	UndoOf string `json:"undoOf,omitempty"`
+}
+
+type auditLog struct {
+	file    *os.File
+	encoder *json.Encoder
+}
+
+func openAuditLog(path string) (*auditLog, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600) //nolint:mnd
+	if err != nil {
+		return nil, err
+	}
+	return &auditLog{file: file, encoder: json.NewEncoder(file)}, nil
+}
+
+func (l *auditLog) write(entry *AuditEntry) error {
+	entry.Time = time.Now().UTC()
+	if err := l.encoder.Encode(entry); err != nil {
+		return err
+	}
+	return l.file.Sync()
+}
+
+func (l *auditLog) Close() error {
+	return l.file.Close()
+}
+
+// readAuditLog returns all the entries of an audit log, oldest first.
+func readAuditLog(path string) ([]AuditEntry, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
@@ -0,0 +1,218 @@
+package bulk
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+	"github.com/edcdavid/jira-helper/internal/jirahelper"
+)
+
+const maxIssuesRetrieved = 50
+
+// Options are shared by the bulk operations.
+type Options struct {
+	AuditLog string
+	// Interval is the minimum time between two updates sent to the server. Zero disables the
+	// throttling.
+	Interval time.Duration
+	// Retries is the number of retries of an update rejected because of rate limiting or a
+	// server error.
+	Retries int
+	// Yes applies the plan without asking for confirmation, DryRun only prints it.
+	Yes    bool
+	DryRun bool
+}
+
+// change moves one issue from one fixVersion to another.
+type change struct {
+	key     string
+	summary string
+	from    string
+	to      string
+	// toPresent tells that to is already a fixVersion of the issue, so only from is removed.
+	toPresent bool
+	// keepFrom tells that from is kept, as an undo of a move to a version the issue already had.
+	keepFrom bool
+}
+
+// MoveVersion moves the issues returned by jql from one fixVersion to another. When jql is
+// empty, the unresolved issues of the from version are moved. The plan is printed and, once
+// confirmed on in, applied and recorded in the audit log.
+func MoveVersion(ctx context.Context, client *jira.Client, jql, from, to string, opts *Options,
+	in io.Reader, out io.Writer) error {
+	if jql == "" {
+		jql = fmt.Sprintf("fixVersion = %q and statusCategory != Done", from)
+	}
+	issues, err := jirahelper.FetchAllIssues(ctx, client, jql, maxIssuesRetrieved)
+	if err != nil {
+		return err
+	}
+
+	var changes []change
+	var skipped []string
+	for i := range issues {
+		issue := &issues[i]
+		versions := fixVersionNames(issue)
+		if !slices.Contains(versions, from) {
+			skipped = append(skipped, issue.Key)
+			continue
+		}
+		changes = append(changes, change{key: issue.Key, summary: issue.Fields.Summary, from: from, to: to,
+			toPresent: slices.Contains(versions, to)})
+	}
+
+	fmt.Fprintf(out, "Plan: move %d issues from fixVersion %s to %s\n", len(changes), from, to)
+	for _, c := range changes {
+		fmt.Fprintf(out, "  %s: %s\n", c.key, c.summary)
+	}
+	if len(skipped) > 0 {
+		fmt.Fprintf(out, "Skipped, %s is not a fixVersion: %s\n", from, strings.Join(skipped, ", "))
+	}
+
+	return apply(ctx, client, changes, operationMoveVersion, newRunID(), "", opts, in, out)
+}
+
+// Undo reverts the changes applied by a move run, the last one when runID is empty, in
+// reverse order.
+func Undo(ctx context.Context, client *jira.Client, runID string, opts *Options, in io.Reader, out io.Writer) error {
+	entries, err := readAuditLog(opts.AuditLog)
+	if err != nil {
+		return err
+	}
+
+	if runID == "" {
+		for _, entry := range entries {
+			if entry.Operation == operationMoveVersion {
+				runID = entry.RunID
+			}
+		}
+		if runID == "" {
+			return fmt.Errorf("no move found in audit log %s", opts.AuditLog)
+		}
+	}
+
+	undone := map[string]bool{}
+	for _, entry := range entries {
+		if entry.Operation == operationUndo && entry.UndoOf == runID && entry.Status == statusApplied {
+			undone[entry.Key] = true
+		}
+	}
+
+	var changes []change
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if entry.RunID != runID || entry.Operation != operationMoveVersion || entry.Status != statusApplied ||
+			undone[entry.Key] {
+			continue
+		}
+		changes = append(changes, change{key: entry.Key, from: entry.To, to: entry.From, keepFrom: entry.ToPresent})
+	}
+
+	fmt.Fprintf(out, "Plan: undo %d changes of run %s\n", len(changes), runID)
+	for _, c := range changes {
+		if c.keepFrom {
+			fmt.Fprintf(out, "  %s: fixVersion %s added back, %s kept\n", c.key, c.to, c.from)
+		} else {
+			fmt.Fprintf(out, "  %s: fixVersion %s -> %s\n", c.key, c.from, c.to)
+		}
+	}
+	return apply(ctx, client, changes, operationUndo, newRunID(), runID, opts, in, out)
+}
+
+func apply(ctx context.Context, client *jira.Client, changes []change, operation, runID, undoOf string,
+	opts *Options, in io.Reader, out io.Writer) error {
+	if opts.Interval < 0 {
+		return fmt.Errorf("invalid interval %s, it must not be negative", opts.Interval)
+	}
+	if len(changes) == 0 || opts.DryRun {
+		fmt.Fprintln(out, "Nothing applied")
+		return nil
+	}
+	if !opts.Yes && !confirm(in, out, fmt.Sprintf("Apply %d changes?", len(changes))) {
+		fmt.Fprintln(out, "Aborted")
+		return nil
+	}
+
+	audit, err := openAuditLog(opts.AuditLog)
+	if err != nil {
+		return err
+	}
+	defer audit.Close()
+
+	var throttle <-chan time.Time
+	if opts.Interval > 0 {
+		ticker := time.NewTicker(opts.Interval)
+		defer ticker.Stop()
+		throttle = ticker.C
+	}
+
+	failures := 0
+	for i, c := range changes {
+		if i > 0 && throttle != nil {
+			<-throttle
+		}
+		err := jirahelper.WithRetry(ctx, opts.Retries, func() (*jira.Response, error) {
+			return client.Issue.UpdateIssue(ctx, c.key, map[string]any{
+				"update": map[string]any{"fixVersions": c.operations()},
+			})
+		})
+
+		entry := &AuditEntry{RunID: runID, Operation: operation, Key: c.key, From: c.from, To: c.to,
+			ToPresent: c.toPresent, Status: statusApplied, UndoOf: undoOf}
+		if err != nil {
+			failures++
+			entry.Status = statusFailed
+			entry.Error = err.Error()
+			fmt.Fprintf(out, "%s: failed: %v\n", c.key, err)
+		} else {
+			fmt.Fprintf(out, "%s: %s -> %s\n", c.key, c.from, c.to)
+		}
+		if err := audit.write(entry); err != nil {
+			return fmt.Errorf("write audit log: %w", err)
+		}
+	}
+
+	fmt.Fprintf(out, "Run %s: %d applied, %d failed, audit log %s\n", runID, len(changes)-failures, failures, opts.AuditLog)
+	if failures > 0 {
+		return errors.New("some changes failed")
+	}
+	return nil
+}
+
+// operations are the fixVersions updates of a change.
+func (c *change) operations() []map[string]any {
+	var operations []map[string]any
+	if !c.keepFrom {
+		operations = append(operations, map[string]any{"remove": map[string]string{"name": c.from}})
+	}
+	if !c.toPresent {
+		operations = append(operations, map[string]any{"add": map[string]string{"name": c.to}})
+	}
+	return operations
+}
+
+func newRunID() string {
+	return time.Now().UTC().Format("20060102T150405.000Z")
+}
+
+func confirm(in io.Reader, out io.Writer, question string) bool {
+	fmt.Fprintf(out, "%s [y/N] ", question)
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+func fixVersionNames(issue *jira.Issue) []string {
+	var names []string
+	for _, version := range issue.Fields.FixVersions {
+		names = append(names, version.Name)
+	}
+	return names
+}
@@ -0,0 +1,198 @@
+package bulk
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+)
+
+// fakeJira serves the search and the fixVersion updates of a set of issues. Updates of the
+// keys in fail are rejected.
+type fakeJira struct {
+	mu       sync.Mutex
+	versions map[string][]string
+	fail     map[string]bool
+}
+
+func (f *fakeJira) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/rest/api/2/search":
+		var issues []map[string]any
+		for _, key := range slices.Sorted(maps.Keys(f.versions)) {
+			var versions []map[string]string
+			for _, name := range f.versions[key] {
+				versions = append(versions, map[string]string{"name": name})
+			}
+			issues = append(issues, map[string]any{"key": key,
+				"fields": map[string]any{"summary": "summary of " + key, "fixVersions": versions}})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"startAt": 0, "maxResults": len(issues),
+			"total": len(issues), "issues": issues})
+	case r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, "/rest/api/2/issue/"):
+		key := strings.TrimPrefix(r.URL.Path, "/rest/api/2/issue/")
+		if f.fail[key] {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = io.WriteString(w, `{"errorMessages":["rejected"]}`)
+			return
+		}
+		var body struct {
+			Update struct {
+				FixVersions []map[string]map[string]string `json:"fixVersions"`
+			} `json:"update"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		for _, op := range body.Update.FixVersions {
+			if remove, ok := op["remove"]; ok {
+				f.versions[key] = slices.DeleteFunc(f.versions[key], func(v string) bool { return v == remove["name"] })
+			}
+			if add, ok := op["add"]; ok {
+				f.versions[key] = append(f.versions[key], add["name"])
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeJira) failOn(keys ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.fail = map[string]bool{}
+	for _, key := range keys {
+		f.fail[key] = true
+	}
+}
+
+func (f *fakeJira) get(key string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Sorted(slices.Values(f.versions[key]))
+}
+
+func TestMoveVersionUndoRoundTrip(t *testing.T) {
+	fake := &fakeJira{
+		versions: map[string][]string{
+			"CNF-1": {"openshift-4.20"},
+			"CNF-2": {"openshift-4.20", "openshift-4.19"},
+			"CNF-3": {"openshift-4.20"},
+			"CNF-4": {"openshift-4.19"},
+			"CNF-5": {"openshift-4.20", "openshift-4.21"},
+		},
+		fail: map[string]bool{"CNF-3": true},
+	}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	client, err := jira.NewClient(server.URL, server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	opts := &Options{AuditLog: filepath.Join(t.TempDir(), "audit.jsonl"), Yes: true}
+
+	err = MoveVersion(ctx, client, "project = CNF", "openshift-4.20", "openshift-4.21", opts, nil, io.Discard)
+	if err == nil {
+		t.Fatal("MoveVersion with a rejected update returned no error")
+	}
+	want := map[string][]string{
+		"CNF-1": {"openshift-4.21"},
+		"CNF-2": {"openshift-4.19", "openshift-4.21"},
+		"CNF-3": {"openshift-4.20"},
+		"CNF-4": {"openshift-4.19"},
+		"CNF-5": {"openshift-4.21"},
+	}
+	for key, versions := range want {
+		if got := fake.get(key); !slices.Equal(got, versions) {
+			t.Errorf("after move %s fixVersions = %v, want %v", key, got, versions)
+		}
+	}
+
+	entries, err := readAuditLog(opts.AuditLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	statuses := map[string]string{}
+	for _, entry := range entries {
+		if entry.Operation != operationMoveVersion || entry.From != "openshift-4.20" || entry.To != "openshift-4.21" {
+			t.Errorf("unexpected audit entry %+v", entry)
+		}
+		if entry.ToPresent != (entry.Key == "CNF-5") {
+			t.Errorf("audit entry of %s has ToPresent %v", entry.Key, entry.ToPresent)
+		}
+		statuses[entry.Key] = entry.Status
+	}
+	wantStatuses := map[string]string{"CNF-1": statusApplied, "CNF-2": statusApplied, "CNF-3": statusFailed,
+		"CNF-5": statusApplied}
+	if len(statuses) != len(wantStatuses) {
+		t.Errorf("audit log keys = %v, want %v", statuses, wantStatuses)
+	}
+	for key, status := range wantStatuses {
+		if statuses[key] != status {
+			t.Errorf("audit status of %s = %q, want %q", key, statuses[key], status)
+		}
+	}
+
+	// The first undo fails on CNF-2, so only CNF-1 and CNF-5 are reverted; the failed move of
+	// CNF-3 is never reverted. CNF-5 keeps openshift-4.21, which it had before the move.
+	fake.failOn("CNF-2")
+	if err := Undo(ctx, client, "", opts, nil, io.Discard); err == nil {
+		t.Fatal("Undo with a rejected update returned no error")
+	}
+	if got := fake.get("CNF-1"); !slices.Equal(got, []string{"openshift-4.20"}) {
+		t.Errorf("after undo CNF-1 fixVersions = %v", got)
+	}
+	if got := fake.get("CNF-2"); !slices.Equal(got, []string{"openshift-4.19", "openshift-4.21"}) {
+		t.Errorf("after failed undo CNF-2 fixVersions = %v", got)
+	}
+	if got := fake.get("CNF-5"); !slices.Equal(got, []string{"openshift-4.20", "openshift-4.21"}) {
+		t.Errorf("after undo CNF-5 fixVersions = %v", got)
+	}
+
+	// The second undo only reverts CNF-2, the entry not yet undone.
+	fake.failOn()
+	var out strings.Builder
+	if err := Undo(ctx, client, entries[0].RunID, opts, nil, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Plan: undo 1 changes") {
+		t.Errorf("second undo plan = %q, want 1 change", out.String())
+	}
+	want["CNF-1"] = []string{"openshift-4.20"}
+	want["CNF-2"] = []string{"openshift-4.19", "openshift-4.20"}
+	want["CNF-5"] = []string{"openshift-4.20", "openshift-4.21"}
+	for key, versions := range want {
+		if got := fake.get(key); !slices.Equal(got, versions) {
+			t.Errorf("after undo %s fixVersions = %v, want %v", key, got, versions)
+		}
+	}
+
+	// Everything applied is undone, so a third undo has nothing to do.
+	out.Reset()
+	if err := Undo(ctx, client, entries[0].RunID, opts, nil, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Plan: undo 0 changes") {
+		t.Errorf("third undo plan = %q, want no change", out.String())
+	}
+
+	opts.Interval = -time.Second
+	if err := Undo(ctx, client, entries[0].RunID, opts, nil, io.Discard); err == nil {
+		t.Error("Undo with a negative interval returned no error")
+	}
+}
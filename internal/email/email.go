@@ -0,0 +1,233 @@
+package email
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/edcdavid/jira-helper/internal/sanitize"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	gmhtml "github.com/yuin/goldmark/renderer/html"
+)
+
+const base64LineLength = 76
+
+var (
+	dataURIRe = regexp.MustCompile(`src="data:(image/[a-z+]+);base64,([A-Za-z0-9+/=]+)"`)
+	imgTagRe  = regexp.MustCompile(`(?i)<img[^>]*>`)
+	brTagRe   = regexp.MustCompile(`(?i)<br\s*/?>`)
+	htmlTagRe = regexp.MustCompile(`<[^>]+>`)
+	blankRe   = regexp.MustCompile(`\n{3,}`)
+)
+
+// Message is the envelope of the email.
+type Message struct {
+	From    string
+	To      []string
+	Subject string
+	Date    time.Time
+}
+
+// image is a data URI image moved to an inline MIME part.
+type image struct {
+	ContentType string
+	ContentID   string
+	Data        []byte
+}
+
+// Build renders the markdown report as a MIME multipart/related message: an alternative of a
+// plain text and an HTML body, followed by the images of the report as inline parts referenced
+// by Content-ID, as data URIs are blocked by some mail clients.
+func Build(markdown string, msg *Message) ([]byte, error) {
+	domain := "jira-helper.local"
+	if from, err := mail.ParseAddress(msg.From); err == nil {
+		domain = from.Address[strings.LastIndex(from.Address, "@")+1:]
+	}
+
+	var images []image
+	var decodeErr error
+	withCIDs := dataURIRe.ReplaceAllStringFunc(markdown, func(match string) string {
+		parts := dataURIRe.FindStringSubmatch(match)
+		data, err := base64.StdEncoding.DecodeString(parts[2])
+		if err != nil {
+			decodeErr = err
+			return match
+		}
+		cid := fmt.Sprintf("chart%d.%s@%s", len(images)+1, randomID(), domain)
+		images = append(images, image{ContentType: parts[1], ContentID: cid, Data: data})
+		return `src="cid:` + cid + `"`
+	})
+	if decodeErr != nil {
+		return nil, fmt.Errorf("invalid image data URI: %w", decodeErr)
+	}
+
+	// The raw HTML of the report is rendered, and filtered by sanitize.HTML.
+	var rendered bytes.Buffer
+	markdownRenderer := goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+		goldmark.WithRendererOptions(gmhtml.WithUnsafe()),
+	)
+	if err := markdownRenderer.Convert([]byte(withCIDs), &rendered); err != nil {
+		return nil, err
+	}
+	var htmlBody bytes.Buffer
+	htmlBody.WriteString("<!DOCTYPE html>\n<html><head><meta charset=\"utf-8\"></head><body style=\"font-family: sans-serif\">\n")
+	htmlBody.WriteString(sanitize.HTML(rendered.String()))
+	htmlBody.WriteString("</body></html>\n")
+
+	var buf bytes.Buffer
+	related := multipart.NewWriter(&buf)
+	writeHeaders(&buf, msg, domain, related.Boundary())
+
+	alternativeBuf := &bytes.Buffer{}
+	alternative := multipart.NewWriter(alternativeBuf)
+	if err := writeQuotedPrintable(alternative, "text/plain; charset=utf-8", plainText(markdown)); err != nil {
+		return nil, err
+	}
+	if err := writeQuotedPrintable(alternative, "text/html; charset=utf-8", htmlBody.String()); err != nil {
+		return nil, err
+	}
+	if err := alternative.Close(); err != nil {
+		return nil, err
+	}
+	part, err := related.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {"multipart/alternative; boundary=" + alternative.Boundary()},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := part.Write(alternativeBuf.Bytes()); err != nil {
+		return nil, err
+	}
+
+	for i, img := range images {
+		extension := strings.TrimPrefix(img.ContentType, "image/")
+		part, err := related.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {img.ContentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-ID":                {"<" + img.ContentID + ">"},
+			"Content-Disposition":       {fmt.Sprintf("inline; filename=\"chart%d.%s\"", i+1, extension)},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeBase64Lines(part, img.Data); err != nil {
+			return nil, err
+		}
+	}
+	if err := related.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeHeaders(buf *bytes.Buffer, msg *Message, domain, boundary string) {
+	date := msg.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
+	headers := []struct{ name, value string }{
+		{"From", msg.From},
+		{"To", strings.Join(msg.To, ", ")},
+		{"Subject", mime.QEncoding.Encode("utf-8", msg.Subject)},
+		{"Date", date.Format(time.RFC1123Z)},
+		{"Message-ID", "<" + randomID() + "." + fmt.Sprint(date.Unix()) + "@" + domain + ">"},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "multipart/related; type=\"multipart/alternative\"; boundary=" + boundary},
+	}
+	for _, header := range headers {
+		if header.value != "" {
+			fmt.Fprintf(buf, "%s: %s\r\n", header.name, header.value)
+		}
+	}
+	buf.WriteString("\r\n")
+}
+
+func writeQuotedPrintable(w *multipart.Writer, contentType, text string) error {
+	part, err := w.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return err
+	}
+	qp := quotedprintable.NewWriter(part)
+	if _, err := qp.Write([]byte(text)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+func writeBase64Lines(w io.Writer, data []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for start := 0; start < len(encoded); start += base64LineLength {
+		end := min(start+base64LineLength, len(encoded))
+		if _, err := w.Write([]byte(encoded[start:end] + "\r\n")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// plainText is the markdown without its charts and HTML markup.
+func plainText(markdown string) string {
+	text := imgTagRe.ReplaceAllString(markdown, "")
+	text = brTagRe.ReplaceAllString(text, "\n")
+	text = html.UnescapeString(htmlTagRe.ReplaceAllString(text, ""))
+	return strings.TrimSpace(blankRe.ReplaceAllString(text, "\n\n")) + "\n"
+}
+
+func randomID() string {
+	b := make([]byte, 8) //nolint:mnd
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// SMTPOptions configures Send. The password is read from JIRA_HELPER_SMTP_PASSWORD.
+type SMTPOptions struct {
+	// Addr is the host:port of the SMTP server. STARTTLS is used when the server offers it.
+	Addr string
+	User string
+}
+
+// Send sends a built message through an SMTP server.
+func Send(opts *SMTPOptions, msg *Message, data []byte) error {
+	from, err := mail.ParseAddress(msg.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender %q: %w", msg.From, err)
+	}
+	to := make([]string, len(msg.To))
+	for i, recipient := range msg.To {
+		address, err := mail.ParseAddress(recipient)
+		if err != nil {
+			return fmt.Errorf("invalid recipient %q: %w", recipient, err)
+		}
+		to[i] = address.Address
+	}
+
+	var auth smtp.Auth
+	if opts.User != "" {
+		host, _, err := net.SplitHostPort(opts.Addr)
+		if err != nil {
+			return err
+		}
+		auth = smtp.PlainAuth("", opts.User, os.Getenv("JIRA_HELPER_SMTP_PASSWORD"), host)
+	}
+	return smtp.SendMail(opts.Addr, auth, from.Address, to, data)
+}
@@ -0,0 +1,182 @@
+package email
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// pixel is a 1x1 GIF.
+var pixel = []byte("GIF89a\x01\x00\x01\x00\x00\x00\x00;")
+
+func reportMarkdown() string {
+	return "<span style=\"background-color:red; color:white\">RED</span>\n" +
+		"  - [CNF-1: Fix <the> pipeline](https://issues.example.com/browse/CNF-1)\n\n" +
+		"<img src=\"data:image/gif;base64," + base64.StdEncoding.EncodeToString(pixel) + "\" width=\"1\" height=\"1\">\n"
+}
+
+// part is a decoded MIME part.
+type part struct {
+	header textproto.MIMEHeader
+	body   []byte
+}
+
+func readParts(t *testing.T, r io.Reader, boundary string) []part {
+	t.Helper()
+	var parts []part
+	reader := multipart.NewReader(r, boundary)
+	for {
+		p, err := reader.NextPart()
+		if err == io.EOF {
+			return parts
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts = append(parts, part{header: p.Header, body: body})
+	}
+}
+
+func TestBuild(t *testing.T) {
+	data, err := Build(reportMarkdown(), &Message{From: "Reports <reports@example.com>",
+		To: []string{"team@example.com"}, Subject: "Release 4.20 report", Date: time.Unix(0, 0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/related" {
+		t.Fatalf("Content-Type = %q, %v, want multipart/related", msg.Header.Get("Content-Type"), err)
+	}
+
+	related := readParts(t, msg.Body, params["boundary"])
+	if len(related) != 2 {
+		t.Fatalf("got %d related parts, want the alternative and one image", len(related))
+	}
+	mediaType, params, err = mime.ParseMediaType(related[0].header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("first part is %q, %v, want multipart/alternative", related[0].header.Get("Content-Type"), err)
+	}
+	alternative := readParts(t, bytes.NewReader(related[0].body), params["boundary"])
+	if len(alternative) != 2 ||
+		!strings.HasPrefix(alternative[0].header.Get("Content-Type"), "text/plain") ||
+		!strings.HasPrefix(alternative[1].header.Get("Content-Type"), "text/html") {
+		t.Fatalf("alternative parts are not text/plain then text/html: %+v", alternative)
+	}
+	// The multipart reader decodes quoted-printable parts.
+	text, htmlBody := string(alternative[0].body), string(alternative[1].body)
+	if strings.Contains(text, "<span") || strings.Contains(text, "<img") || !strings.Contains(text, "RED") {
+		t.Errorf("plain text body = %q", text)
+	}
+	if strings.Contains(htmlBody, "data:image") || !strings.Contains(htmlBody, "Fix &lt;the&gt; pipeline") {
+		t.Errorf("HTML body = %q", htmlBody)
+	}
+
+	image := related[1]
+	contentID := strings.Trim(image.header.Get("Content-ID"), "<>")
+	if !regexp.MustCompile(`^chart1\.[0-9a-f]+@example\.com$`).MatchString(contentID) {
+		t.Errorf("Content-ID = %q", contentID)
+	}
+	if !strings.Contains(htmlBody, `src="cid:`+contentID+`"`) {
+		t.Errorf("HTML body does not reference cid:%s: %q", contentID, htmlBody)
+	}
+	if image.header.Get("Content-Type") != "image/gif" {
+		t.Errorf("image Content-Type = %q", image.header.Get("Content-Type"))
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(string(image.body), "\r\n", ""))
+	if err != nil || !bytes.Equal(decoded, pixel) {
+		t.Errorf("image data = %q, %v, want the data URI content", decoded, err)
+	}
+}
+
+// smtpStub accepts one message on a listener and returns its envelope and data.
+func smtpStub(t *testing.T, listener net.Listener) <-chan string {
+	t.Helper()
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			received <- err.Error()
+			return
+		}
+		defer conn.Close()
+		var transcript strings.Builder
+		text := textproto.NewConn(conn)
+		_ = text.PrintfLine("220 stub ESMTP")
+		for {
+			line, err := text.ReadLine()
+			if err != nil {
+				received <- transcript.String()
+				return
+			}
+			command := strings.ToUpper(strings.Fields(line + " ")[0])
+			switch command {
+			case "EHLO", "HELO":
+				_ = text.PrintfLine("250 stub")
+			case "MAIL", "RCPT":
+				fmt.Fprintln(&transcript, line)
+				_ = text.PrintfLine("250 OK")
+			case "DATA":
+				_ = text.PrintfLine("354 Go ahead")
+				data, err := text.ReadDotBytes()
+				if err != nil {
+					received <- err.Error()
+					return
+				}
+				transcript.Write(data)
+				_ = text.PrintfLine("250 Queued")
+			case "QUIT":
+				_ = text.PrintfLine("221 Bye")
+				received <- transcript.String()
+				return
+			default:
+				_ = text.PrintfLine("502 Unknown command")
+			}
+		}
+	}()
+	return received
+}
+
+func TestSend(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	received := smtpStub(t, listener)
+
+	msg := &Message{From: "Reports <reports@example.com>", To: []string{"Team <team@example.com>", "lead@example.com"},
+		Subject: "Release 4.20 report"}
+	data, err := Build("Nothing new.", msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Send(&SMTPOptions{Addr: listener.Addr().String()}, msg, data); err != nil {
+		t.Fatal(err)
+	}
+
+	transcript := <-received
+	for _, want := range []string{"MAIL FROM:<reports@example.com>", "RCPT TO:<team@example.com>",
+		"RCPT TO:<lead@example.com>", "Subject: Release 4.20 report", "Content-Type: multipart/related"} {
+		if !strings.Contains(transcript, want) {
+			t.Errorf("transcript does not contain %q:\n%s", want, transcript)
+		}
+	}
+}
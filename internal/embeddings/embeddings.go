@@ -0,0 +1,122 @@
+package embeddings
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"math"
+	"os"
+	"sort"
+
+	"github.com/edcdavid/jira-helper/internal/ollamahelper"
+)
+
+const batchSize = 16
+
+// Store is the on-disk cache of issue embeddings. A record is only recomputed when the hash
+// of the issue text changes, or when the store was built with another model.
+type Store struct {
+	Model  string            `json:"model"`
+	Issues map[string]Record `json:"issues"`
+}
+
+type Record struct {
+	Hash   string    `json:"hash"`
+	Vector []float32 `json:"vector"`
+}
+
+// Load reads the store at path. A missing file, or a file built with another model, gives an
+// empty store for model.
+func Load(path, model string) (*Store, error) {
+	store := &Store{Model: model, Issues: map[string]Record{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	loaded := &Store{}
+	if err := json.Unmarshal(data, loaded); err != nil {
+		return nil, err
+	}
+	if loaded.Model != model || loaded.Issues == nil {
+		return store, nil
+	}
+	return loaded, nil
+}
+
+func (s *Store) Save(path string) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600) //nolint:mnd
+}
+
+// Update computes the embeddings of the texts, keyed by issue key, whose hash changed since
+// they were stored. It returns the number of embeddings computed.
+func (s *Store) Update(ctx context.Context, texts map[string]string) (int, error) {
+	var keys []string
+	for key, text := range texts {
+		if s.Issues[key].Hash != hash(text) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for start := 0; start < len(keys); start += batchSize {
+		batch := keys[start:min(start+batchSize, len(keys))]
+		inputs := make([]string, len(batch))
+		for i, key := range batch {
+			inputs[i] = texts[key]
+		}
+		vectors, err := ollamahelper.Embed(ctx, s.Model, inputs)
+		if err != nil {
+			return start, err
+		}
+		for i, key := range batch {
+			s.Issues[key] = Record{Hash: hash(texts[key]), Vector: vectors[i]}
+		}
+	}
+	return len(keys), nil
+}
+
+// Prune drops the records of the issues missing from texts, the issues that left the query, and
+// returns the number of records dropped.
+func (s *Store) Prune(texts map[string]string) int {
+	pruned := 0
+	for key := range s.Issues {
+		if _, ok := texts[key]; !ok {
+			delete(s.Issues, key)
+			pruned++
+		}
+	}
+	return pruned
+}
+
+func hash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// Cosine returns the cosine similarity of two vectors, or 0 when they cannot be compared.
+func Cosine(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
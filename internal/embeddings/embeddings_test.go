@@ -0,0 +1,44 @@
+package embeddings
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestPruneSaveLoad(t *testing.T) {
+	store := &Store{Model: "model", Issues: map[string]Record{
+		"CNF-1": {Hash: hash("one"), Vector: []float32{1, 0}},
+		"CNF-2": {Hash: hash("two"), Vector: []float32{0, 1}},
+		"CNF-3": {Hash: hash("three"), Vector: []float32{1, 1}},
+	}}
+	if pruned := store.Prune(map[string]string{"CNF-1": "one", "CNF-3": "three"}); pruned != 1 {
+		t.Errorf("Prune dropped %d records, want 1", pruned)
+	}
+
+	path := filepath.Join(t.TempDir(), "embeddings.json")
+	if err := store.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(path, "model")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	for key := range loaded.Issues {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	if !slices.Equal(keys, []string{"CNF-1", "CNF-3"}) {
+		t.Errorf("saved store has %v, want [CNF-1 CNF-3]", keys)
+	}
+
+	// A store built with another model is not reused.
+	other, err := Load(path, "other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(other.Issues) != 0 {
+		t.Errorf("store of another model has %d records", len(other.Issues))
+	}
+}
@@ -0,0 +1,197 @@
+package graph
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Analysis is what the blocking links tell about the graph.
+type Analysis struct {
+	// Cycles are issues blocking each other, each given as a path back to its first issue.
+	Cycles [][]string
+	// CriticalPath is the longest chain of open issues blocking each other, blockers first.
+	CriticalPath []string
+}
+
+// Analyze finds the cycles of blocking links and the critical path. Links inside a cycle are
+// ignored for the critical path, as the chain would never end.
+func (g *Graph) Analyze() Analysis {
+	blocking := map[string][]string{}
+	for _, edge := range g.Edges {
+		if edge.Blocking {
+			blocking[edge.From] = append(blocking[edge.From], edge.To)
+		}
+	}
+
+	var analysis Analysis
+	component := map[string]int{}
+	for i, scc := range stronglyConnected(keys(g.Nodes), blocking) {
+		for _, key := range scc {
+			component[key] = i
+		}
+		if len(scc) > 1 {
+			analysis.Cycles = append(analysis.Cycles, cyclePath(scc, blocking))
+		}
+	}
+	analysis.CriticalPath = g.longestOpenChain(blocking, component)
+	return analysis
+}
+
+// stronglyConnected returns the strongly connected components of the graph with Tarjan's
+// algorithm, in reverse topological order.
+func stronglyConnected(nodes []string, edges map[string][]string) [][]string {
+	index := map[string]int{}
+	lowLink := map[string]int{}
+	onStack := map[string]bool{}
+	var stack []string
+	var components [][]string
+
+	var visit func(node string)
+	visit = func(node string) {
+		index[node] = len(index)
+		lowLink[node] = index[node]
+		stack = append(stack, node)
+		onStack[node] = true
+		for _, next := range edges[node] {
+			if _, visited := index[next]; !visited {
+				visit(next)
+				lowLink[node] = min(lowLink[node], lowLink[next])
+			} else if onStack[next] {
+				lowLink[node] = min(lowLink[node], index[next])
+			}
+		}
+		if lowLink[node] != index[node] {
+			return
+		}
+		var component []string
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == node {
+				break
+			}
+		}
+		sort.Strings(component)
+		components = append(components, component)
+	}
+	for _, node := range nodes {
+		if _, visited := index[node]; !visited {
+			visit(node)
+		}
+	}
+	return components
+}
+
+// cyclePath returns the shortest cycle through the first issue of a strongly connected
+// component, ending with that issue again.
+func cyclePath(component []string, edges map[string][]string) []string {
+	start := component[0]
+	inComponent := map[string]bool{}
+	for _, key := range component {
+		inComponent[key] = true
+	}
+	previous := map[string]string{}
+	queue := []string{start}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, next := range edges[node] {
+			if !inComponent[next] {
+				continue
+			}
+			if next == start {
+				path := []string{start}
+				for at := node; at != start; at = previous[at] {
+					path = append(path, at)
+				}
+				path = append(path, start)
+				// The path was built backwards from the end.
+				for i, j := 1, len(path)-2; i < j; i, j = i+1, j-1 {
+					path[i], path[j] = path[j], path[i]
+				}
+				return path
+			}
+			if _, seen := previous[next]; !seen {
+				previous[next] = node
+				queue = append(queue, next)
+			}
+		}
+	}
+	return component
+}
+
+// longestOpenChain returns the longest path of open issues following the blocking links that
+// do not belong to a cycle.
+func (g *Graph) longestOpenChain(edges map[string][]string, component map[string]int) []string {
+	length := map[string]int{}
+	next := map[string]string{}
+	var longest func(node string) int
+	longest = func(node string) int {
+		if l, ok := length[node]; ok {
+			return l
+		}
+		length[node] = 1
+		for _, to := range edges[node] {
+			if component[to] == component[node] || g.Nodes[to].Done {
+				continue
+			}
+			if l := longest(to) + 1; l > length[node] {
+				length[node] = l
+				next[node] = to
+			}
+		}
+		return length[node]
+	}
+
+	var start string
+	for _, key := range keys(g.Nodes) {
+		if g.Nodes[key].Done {
+			continue
+		}
+		if start == "" || longest(key) > longest(start) {
+			start = key
+		}
+	}
+	if start == "" || length[start] < 2 { //nolint:mnd
+		return nil
+	}
+	path := []string{start}
+	for at := start; next[at] != ""; at = next[at] {
+		path = append(path, next[at])
+	}
+	return path
+}
+
+// WriteText writes the cycles and the critical path in plain text.
+func (g *Graph) WriteText(w io.Writer, analysis *Analysis) {
+	blocking := 0
+	for _, edge := range g.Edges {
+		if edge.Blocking {
+			blocking++
+		}
+	}
+	fmt.Fprintf(w, "%d issues, %d links, %d blocking\n", len(g.Nodes), len(g.Edges), blocking)
+	if len(analysis.Cycles) == 0 {
+		fmt.Fprintln(w, "No blocking cycle")
+	}
+	for _, cycle := range analysis.Cycles {
+		fmt.Fprintf(w, "Blocking cycle: %s\n", strings.Join(cycle, " -> "))
+	}
+	if len(analysis.CriticalPath) == 0 {
+		fmt.Fprintln(w, "No open issue blocks another one")
+		return
+	}
+	fmt.Fprintf(w, "Critical path (%d open issues):\n", len(analysis.CriticalPath))
+	for _, key := range analysis.CriticalPath {
+		node := g.Nodes[key]
+		if node.Color != "" {
+			fmt.Fprintf(w, "  %s %s: %s\n", node, node.Color, node.Summary)
+		} else {
+			fmt.Fprintf(w, "  %s: %s\n", node, node.Summary)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package graph
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files of testdata")
+
+// fixture builds a graph of open issues with the blocking links given as from, to pairs.
+func fixture(done []string, links ...[2]string) *Graph {
+	g := &Graph{Nodes: map[string]*Node{}}
+	for _, link := range links {
+		for _, key := range link {
+			if g.Nodes[key] == nil {
+				g.Nodes[key] = &Node{Key: key, Project: "CNF", Done: slices.Contains(done, key)}
+			}
+		}
+		g.Edges = append(g.Edges, Edge{From: link[0], To: link[1], Type: "Blocks", Label: "blocks", Blocking: true})
+	}
+	return g
+}
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name         string
+		graph        *Graph
+		cycles       [][]string
+		criticalPath []string
+	}{
+		{
+			name:   "3-cycle",
+			graph:  fixture(nil, [2]string{"A", "B"}, [2]string{"B", "C"}, [2]string{"C", "A"}),
+			cycles: [][]string{{"A", "B", "C", "A"}},
+		},
+		{
+			name: "shortest cycle through the first issue",
+			graph: fixture(nil, [2]string{"A", "B"}, [2]string{"B", "C"}, [2]string{"C", "D"}, [2]string{"D", "A"},
+				[2]string{"B", "A"}),
+			cycles: [][]string{{"A", "B", "A"}},
+		},
+		{
+			name: "diamond",
+			graph: fixture(nil, [2]string{"A", "B"}, [2]string{"A", "C"}, [2]string{"B", "D"},
+				[2]string{"C", "D"}),
+			criticalPath: []string{"A", "B", "D"},
+		},
+		{
+			name: "chain through a done issue",
+			graph: fixture([]string{"B"}, [2]string{"A", "B"}, [2]string{"B", "C"}, [2]string{"C", "D"},
+				[2]string{"D", "E"}),
+			criticalPath: []string{"C", "D", "E"},
+		},
+		{
+			name:         "cycle feeding a chain",
+			graph:        fixture(nil, [2]string{"A", "B"}, [2]string{"B", "A"}, [2]string{"B", "C"}, [2]string{"C", "D"}),
+			cycles:       [][]string{{"A", "B", "A"}},
+			criticalPath: []string{"B", "C", "D"},
+		},
+		{
+			name: "no blocking link",
+			graph: &Graph{Nodes: map[string]*Node{"A": {Key: "A"}, "B": {Key: "B"}},
+				Edges: []Edge{{From: "A", To: "B", Type: "Relates", Label: "relates to"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analysis := tt.graph.Analyze()
+			if !slices.EqualFunc(analysis.Cycles, tt.cycles, slices.Equal) {
+				t.Errorf("Cycles = %v, want %v", analysis.Cycles, tt.cycles)
+			}
+			if !slices.Equal(analysis.CriticalPath, tt.criticalPath) {
+				t.Errorf("CriticalPath = %v, want %v", analysis.CriticalPath, tt.criticalPath)
+			}
+		})
+	}
+}
+
+func TestStronglyConnected(t *testing.T) {
+	edges := map[string][]string{"A": {"B"}, "B": {"C", "D"}, "C": {"A"}, "D": {"E"}, "E": {"D"}}
+	got := stronglyConnected([]string{"A", "B", "C", "D", "E", "F"}, edges)
+	// The components reached from a component come first.
+	want := [][]string{{"D", "E"}, {"A", "B", "C"}, {"F"}}
+	if !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("stronglyConnected = %v, want %v", got, want)
+	}
+}
+
+// renderFixture has two projects, issues in every state, a label to escape, a cycle and a
+// critical path.
+func renderFixture() *Graph {
+	return &Graph{
+		Nodes: map[string]*Node{
+			"CNF-1": {Key: "CNF-1", Project: "CNF", Summary: `Fix the "ptp" | <operator> upgrade path on SNO`,
+				Color: "Red", Status: "In Progress", InProgress: true},
+			"CNF-2":      {Key: "CNF-2", Project: "CNF", Summary: "Document the upgrade", Color: "Green", Status: "New"},
+			"CNF-3":      {Key: "CNF-3", Project: "CNF", Summary: "Old fix", Status: "Closed", Done: true},
+			"OCPBUGS-10": {Key: "OCPBUGS-10", Project: "OCPBUGS", Summary: "Image pull fails", Status: "New", Depth: 1},
+			"OCPBUGS-11": {Key: "OCPBUGS-11", Project: "OCPBUGS", Summary: "Registry timeout", Status: "ASSIGNED",
+				Color: "Yellow", Depth: 1},
+		},
+		Edges: []Edge{
+			{From: "CNF-1", To: "CNF-2", Type: "Blocks", Label: "blocks", Blocking: true},
+			{From: "CNF-3", To: "CNF-1", Type: "Relates", Label: "relates to"},
+			{From: "OCPBUGS-10", To: "CNF-1", Type: "Blocks", Label: "blocks", Blocking: true},
+			{From: "OCPBUGS-10", To: "OCPBUGS-11", Type: "Blocks", Label: "blocks", Blocking: true},
+			{From: "OCPBUGS-11", To: "OCPBUGS-10", Type: "Blocks", Label: "blocks", Blocking: true},
+		},
+	}
+}
+
+func TestWriteGolden(t *testing.T) {
+	g := renderFixture()
+	analysis := g.Analyze()
+	for _, format := range []string{FormatDOT, FormatMermaid} {
+		t.Run(format, func(t *testing.T) {
+			var sb strings.Builder
+			if err := g.Write(&sb, format, &analysis); err != nil {
+				t.Fatal(err)
+			}
+			golden := filepath.Join("testdata", "graph."+format)
+			if *update {
+				if err := os.WriteFile(golden, []byte(sb.String()), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sb.String() != string(want) {
+				t.Errorf("%s output differs from %s, run go test -update:\n%s", format, golden, sb.String())
+			}
+		})
+	}
+}
+
+func TestMermaidText(t *testing.T) {
+	if got, want := mermaidText(`a "b" | <c>`), "a #quot;b#quot; #124; #lt;c#gt;"; got != want {
+		t.Errorf("mermaidText = %q, want %q", got, want)
+	}
+}
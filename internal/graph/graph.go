@@ -0,0 +1,295 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+	"github.com/edcdavid/jira-helper/internal/jirahelper"
+	"github.com/edcdavid/jira-helper/internal/reports"
+)
+
+const (
+	maxIssuesRetrieved = 50
+	// fetchBatchSize is the number of linked issues fetched with one key in (...) query.
+	fetchBatchSize = 50
+)
+
+// Node is an issue of the graph.
+type Node struct {
+	Key     string
+	Summary string
+	Project string
+	Color   string
+	Status  string
+	// Done and InProgress are given by the status category.
+	Done       bool
+	InProgress bool
+	// Depth is the number of links followed from the query result to reach the issue.
+	Depth int
+}
+
+// Edge is a link between two issues, oriented from the outward side: for a Blocks link, From
+// blocks To.
+type Edge struct {
+	From     string
+	To       string
+	Type     string
+	Label    string
+	Blocking bool
+}
+
+// Graph is the issues of a query and the issues linked to them.
+type Graph struct {
+	Nodes map[string]*Node
+	Edges []Edge
+}
+
+// Build fetches the issues of the query and follows their links up to depth hops. Only the
+// link types listed are followed, all of them when linkTypes is empty.
+func Build(ctx context.Context, client *jira.Client, jql string, depth int, linkTypes []string) (*Graph, error) {
+	issues, err := jirahelper.FetchAllIssues(ctx, client, jql, maxIssuesRetrieved)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &Graph{Nodes: map[string]*Node{}}
+	fetched := map[string]*jira.Issue{}
+	for i := range issues {
+		fetched[issues[i].Key] = &issues[i]
+		g.Nodes[issues[i].Key] = newNode(&issues[i], 0)
+	}
+
+	frontier := keys(fetched)
+	for level := 1; level <= depth && len(frontier) > 0; level++ {
+		var next []string
+		for _, key := range frontier {
+			for _, linked := range linkedKeys(fetched[key], linkTypes) {
+				if _, ok := fetched[linked]; !ok && !contains(next, linked) {
+					next = append(next, linked)
+				}
+			}
+		}
+		linked, err := fetchKeys(ctx, client, next)
+		if err != nil {
+			return nil, err
+		}
+		frontier = nil
+		for i := range linked {
+			fetched[linked[i].Key] = &linked[i]
+			g.Nodes[linked[i].Key] = newNode(&linked[i], level)
+			frontier = append(frontier, linked[i].Key)
+		}
+	}
+
+	seen := map[Edge]bool{}
+	for _, key := range keys(fetched) {
+		for _, link := range fetched[key].Fields.IssueLinks {
+			if !followed(link, linkTypes) {
+				continue
+			}
+			edge := Edge{Type: link.Type.Name, Label: link.Type.Outward, Blocking: isBlocking(link)}
+			switch {
+			case link.OutwardIssue != nil:
+				edge.From, edge.To = key, link.OutwardIssue.Key
+			case link.InwardIssue != nil:
+				edge.From, edge.To = link.InwardIssue.Key, key
+			default:
+				continue
+			}
+			if g.Nodes[edge.From] == nil || g.Nodes[edge.To] == nil || seen[edge] {
+				continue
+			}
+			seen[edge] = true
+			g.Edges = append(g.Edges, edge)
+		}
+	}
+	sort.Slice(g.Edges, func(i, j int) bool {
+		if g.Edges[i].From != g.Edges[j].From {
+			return g.Edges[i].From < g.Edges[j].From
+		}
+		return g.Edges[i].To < g.Edges[j].To
+	})
+	return g, nil
+}
+
+func newNode(issue *jira.Issue, depth int) *Node {
+	node := &Node{Key: issue.Key, Summary: issue.Fields.Summary, Depth: depth}
+	if issue.Fields.Project.Key != "" {
+		node.Project = issue.Fields.Project.Key
+	} else {
+		node.Project = strings.SplitN(issue.Key, "-", 2)[0] //nolint:mnd
+	}
+	if issue.Fields.Status != nil {
+		node.Status = issue.Fields.Status.Name
+		node.Done = issue.Fields.Status.StatusCategory.Key == jira.StatusCategoryComplete
+		node.InProgress = issue.Fields.Status.StatusCategory.Key == jira.StatusCategoryInProgress
+	}
+	if value, ok := issue.Fields.Unknowns[jirahelper.ColorField].(map[string]any); ok {
+		node.Color, _ = value["value"].(string)
+	}
+	return node
+}
+
+// fetchKeys fetches the issues of the keys given. Jira rejects a whole key in (...) query when
+// one of its keys does not exist or is not visible, as a link to a restricted project, so the
+// keys of a rejected batch are fetched one by one and the ones still rejected are skipped.
+func fetchKeys(ctx context.Context, client *jira.Client, issueKeys []string) ([]jira.Issue, error) {
+	var issues []jira.Issue
+	for start := 0; start < len(issueKeys); start += fetchBatchSize {
+		batch := issueKeys[start:min(start+fetchBatchSize, len(issueKeys))]
+		page, err := searchKeys(ctx, client, batch)
+		if errors.Is(err, errRejectedKeys) {
+			page = nil
+			for _, key := range batch {
+				issue, err := searchKeys(ctx, client, []string{key})
+				if errors.Is(err, errRejectedKeys) {
+					log.Printf("Skipping linked issue %s, err:%v", key, err)
+					continue
+				}
+				if err != nil {
+					return nil, err
+				}
+				page = append(page, issue...)
+			}
+		} else if err != nil {
+			return nil, err
+		}
+		issues = append(issues, page...)
+	}
+	return issues, nil
+}
+
+// errRejectedKeys is returned by searchKeys when Jira rejects the query as invalid.
+var errRejectedKeys = errors.New("keys rejected by Jira")
+
+// searchKeys fetches at most fetchBatchSize keys with a single search.
+func searchKeys(ctx context.Context, client *jira.Client, issueKeys []string) ([]jira.Issue, error) {
+	issues, resp, err := client.Issue.Search(ctx, "key in ("+strings.Join(issueKeys, ", ")+")",
+		&jira.SearchOptions{MaxResults: len(issueKeys)})
+	if err != nil && resp != nil && resp.StatusCode == http.StatusBadRequest {
+		return nil, fmt.Errorf("%w: %w", errRejectedKeys, err)
+	}
+	return issues, err
+}
+
+func linkedKeys(issue *jira.Issue, linkTypes []string) []string {
+	var result []string
+	for _, link := range issue.Fields.IssueLinks {
+		if !followed(link, linkTypes) {
+			continue
+		}
+		if link.OutwardIssue != nil {
+			result = append(result, link.OutwardIssue.Key)
+		}
+		if link.InwardIssue != nil {
+			result = append(result, link.InwardIssue.Key)
+		}
+	}
+	return result
+}
+
+func followed(link *jira.IssueLink, linkTypes []string) bool {
+	if len(linkTypes) == 0 {
+		return true
+	}
+	for _, name := range linkTypes {
+		if strings.EqualFold(name, link.Type.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// isBlocking tells whether the outward issue of a link cannot complete before the inward one:
+// the Blocks link type, or a type worded the same way.
+func isBlocking(link *jira.IssueLink) bool {
+	return link.Type.Name == "Blocks" || strings.EqualFold(link.Type.Outward, "blocks")
+}
+
+func keys[T any](m map[string]T) []string {
+	result := make([]string, 0, len(m))
+	for key := range m {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedNodes returns the nodes ordered by project and key.
+func (g *Graph) sortedNodes() []*Node {
+	nodes := make([]*Node, 0, len(g.Nodes))
+	for _, key := range keys(g.Nodes) {
+		nodes = append(nodes, g.Nodes[key])
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].Project < nodes[j].Project
+	})
+	return nodes
+}
+
+// String describes a node for the text report.
+func (n *Node) String() string {
+	return fmt.Sprintf("%s (%s)", n.Key, n.Status)
+}
+
+// Options configures Run.
+type Options struct {
+	Report reports.ReportOptions
+	// Depth is the number of links followed from the issues of the report query.
+	Depth     int
+	LinkTypes []string
+	// Format is the format written to the output when Output is empty: dot or mermaid.
+	Format string
+	// Output is a file rendered through canvas, in the format of its extension.
+	Output string
+}
+
+// Run builds the graph of the report query and writes it to out, or to the output file, and
+// the cycles and critical path to report.
+func Run(ctx context.Context, opts *Options, out, report io.Writer) error {
+	query, err := reports.ReportQuery(&opts.Report)
+	if err != nil {
+		return err
+	}
+	if opts.Output != "" {
+		if _, err := fileWriter(opts.Output); err != nil {
+			return err
+		}
+	} else if opts.Format != FormatDOT && opts.Format != FormatMermaid {
+		return fmt.Errorf("unknown graph format %q, use %s or %s", opts.Format, FormatDOT, FormatMermaid)
+	}
+	client, err := jirahelper.NewClient(opts.Report.JiraURL, opts.Report.PersonalAccessToken)
+	if err != nil {
+		return err
+	}
+	g, err := Build(ctx, client, query, opts.Depth, opts.LinkTypes)
+	if err != nil {
+		return err
+	}
+	analysis := g.Analyze()
+	if opts.Output != "" {
+		if err := g.RenderFile(opts.Output, &analysis); err != nil {
+			return err
+		}
+	} else if err := g.Write(out, opts.Format, &analysis); err != nil {
+		return err
+	}
+	g.WriteText(report, &analysis)
+	return nil
+}
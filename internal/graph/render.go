@@ -0,0 +1,174 @@
+package graph
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Output formats.
+const (
+	FormatDOT     = "dot"
+	FormatMermaid = "mermaid"
+)
+
+const (
+	colorRed    = "Red"
+	colorYellow = "Yellow"
+	colorGreen  = "Green"
+
+	summaryLength = 40
+
+	criticalColor = "#D62728"
+	cycleColor    = "#FF7F0E"
+)
+
+// fillColor is the background of a node, given by its color field.
+func fillColor(color string) string {
+	switch color {
+	case colorRed:
+		return "#FFB3B3"
+	case colorYellow:
+		return "#FFE699"
+	case colorGreen:
+		return "#B3E6B3"
+	default:
+		return "#FFFFFF"
+	}
+}
+
+// highlights returns the edges of the critical path and of the cycles, to draw them apart.
+func highlights(analysis *Analysis) (critical, cycle map[[2]string]bool) {
+	critical = map[[2]string]bool{}
+	for i := 1; i < len(analysis.CriticalPath); i++ {
+		critical[[2]string{analysis.CriticalPath[i-1], analysis.CriticalPath[i]}] = true
+	}
+	cycle = map[[2]string]bool{}
+	for _, path := range analysis.Cycles {
+		for i := 1; i < len(path); i++ {
+			cycle[[2]string{path[i-1], path[i]}] = true
+		}
+	}
+	return critical, cycle
+}
+
+func truncate(text string, length int) string {
+	runes := []rune(text)
+	if len(runes) <= length {
+		return text
+	}
+	return string(runes[:length-1]) + "…"
+}
+
+// Write writes the graph in the DOT or Mermaid format.
+func (g *Graph) Write(w io.Writer, format string, analysis *Analysis) error {
+	switch format {
+	case FormatDOT:
+		g.writeDOT(w, analysis)
+	case FormatMermaid:
+		g.writeMermaid(w, analysis)
+	default:
+		return fmt.Errorf("unknown graph format %q, use %s or %s", format, FormatDOT, FormatMermaid)
+	}
+	return nil
+}
+
+// writeDOT writes a Graphviz digraph with one cluster per project.
+func (g *Graph) writeDOT(w io.Writer, analysis *Analysis) {
+	critical, cycle := highlights(analysis)
+	fmt.Fprintln(w, "digraph issues {")
+	fmt.Fprintln(w, `  rankdir=LR;`)
+	fmt.Fprintln(w, `  node [shape=box, style="rounded,filled", fontname="sans-serif", fontsize=10];`)
+	fmt.Fprintln(w, `  edge [fontname="sans-serif", fontsize=9];`)
+	project := ""
+	for _, node := range g.sortedNodes() {
+		if node.Project != project {
+			if project != "" {
+				fmt.Fprintln(w, "  }")
+			}
+			project = node.Project
+			fmt.Fprintf(w, "  subgraph %q {\n    label=%q;\n    style=dashed;\n", "cluster_"+project, project)
+		}
+		style := "rounded,filled"
+		switch {
+		case node.Done:
+			style += ",dashed"
+		case node.InProgress:
+			style += ",bold"
+		}
+		fmt.Fprintf(w, "    %q [label=%q, fillcolor=%q, style=%q];\n", node.Key,
+			node.Key+"\n"+truncate(node.Summary, summaryLength)+"\n"+node.Status, fillColor(node.Color), style)
+	}
+	if project != "" {
+		fmt.Fprintln(w, "  }")
+	}
+	for _, edge := range g.Edges {
+		attrs := []string{fmt.Sprintf("label=%q", edge.Label)}
+		if !edge.Blocking {
+			attrs = append(attrs, "style=dashed", `color="#888888"`)
+		}
+		switch key := [2]string{edge.From, edge.To}; {
+		case critical[key]:
+			attrs = append(attrs, fmt.Sprintf("color=%q", criticalColor), "penwidth=2.5")
+		case cycle[key]:
+			attrs = append(attrs, fmt.Sprintf("color=%q", cycleColor), "penwidth=2.5")
+		}
+		fmt.Fprintf(w, "  %q -> %q [%s];\n", edge.From, edge.To, strings.Join(attrs, ", "))
+	}
+	fmt.Fprintln(w, "}")
+}
+
+// writeMermaid writes a Mermaid flowchart with one subgraph per project.
+func (g *Graph) writeMermaid(w io.Writer, analysis *Analysis) {
+	critical, cycle := highlights(analysis)
+	fmt.Fprintln(w, "flowchart LR")
+	project := ""
+	var styles []string
+	for _, node := range g.sortedNodes() {
+		if node.Project != project {
+			if project != "" {
+				fmt.Fprintln(w, "  end")
+			}
+			project = node.Project
+			fmt.Fprintf(w, "  subgraph %s [%s]\n", mermaidID(project+"_project"), project)
+		}
+		fmt.Fprintf(w, "    %s[\"%s<br/>%s<br/><i>%s</i>\"]\n", mermaidID(node.Key), node.Key,
+			mermaidText(truncate(node.Summary, summaryLength)), mermaidText(node.Status))
+		style := "fill:" + fillColor(node.Color) + ",stroke:#333333"
+		switch {
+		case node.Done:
+			style += ",stroke-dasharray:5 5,color:#777777"
+		case node.InProgress:
+			style += ",stroke-width:3px"
+		}
+		styles = append(styles, fmt.Sprintf("  style %s %s", mermaidID(node.Key), style))
+	}
+	if project != "" {
+		fmt.Fprintln(w, "  end")
+	}
+	for i, edge := range g.Edges {
+		arrow := "-->"
+		if !edge.Blocking {
+			arrow = "-.->"
+		}
+		fmt.Fprintf(w, "  %s %s|%s| %s\n", mermaidID(edge.From), arrow, mermaidText(edge.Label), mermaidID(edge.To))
+		switch key := [2]string{edge.From, edge.To}; {
+		case critical[key]:
+			styles = append(styles, fmt.Sprintf("  linkStyle %d stroke:%s,stroke-width:3px", i, criticalColor))
+		case cycle[key]:
+			styles = append(styles, fmt.Sprintf("  linkStyle %d stroke:%s,stroke-width:3px", i, cycleColor))
+		}
+	}
+	for _, style := range styles {
+		fmt.Fprintln(w, style)
+	}
+}
+
+func mermaidID(key string) string {
+	return strings.NewReplacer("-", "_", ".", "_", " ", "_").Replace(key)
+}
+
+// mermaidText escapes the characters ending a Mermaid label.
+func mermaidText(text string) string {
+	return strings.NewReplacer(`"`, "#quot;", "|", "#124;", "<", "#lt;", ">", "#gt;").Replace(text)
+}
@@ -0,0 +1,250 @@
+package graph
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/canvas/renderers/pdf"
+	"github.com/tdewolff/canvas/renderers/rasterizer"
+	"github.com/tdewolff/canvas/renderers/svg"
+)
+
+const (
+	nodeWidth   = 200
+	nodeHeight  = 54
+	layerGap    = 90
+	rowGap      = 16
+	bandPadding = 24
+	margin      = 10
+	arrowSize   = 6
+	// curveOffset is also the left padding of the bands, where links back to the first layer loop.
+	curveOffset = 60
+
+	svgSummaryLength = 32
+
+	dpi         = 200.0
+	jpegQuality = 85
+)
+
+type position struct {
+	x, y float64
+}
+
+// layout places the issues left to right by the length of the chain of issues linked to them,
+// with one horizontal band per project.
+func (g *Graph) layout(analysis *Analysis) (positions map[string]position, bands []svgBand, width, height float64) {
+	incoming := map[string][]string{}
+	for _, edge := range g.Edges {
+		incoming[edge.To] = append(incoming[edge.To], edge.From)
+	}
+	_, cycle := highlights(analysis)
+	layer := map[string]int{}
+	var depth func(key string, visiting map[string]bool) int
+	depth = func(key string, visiting map[string]bool) int {
+		if l, ok := layer[key]; ok {
+			return l
+		}
+		visiting[key] = true
+		l := 0
+		for _, from := range incoming[key] {
+			if visiting[from] || cycle[[2]string{from, key}] {
+				continue
+			}
+			l = max(l, depth(from, visiting)+1)
+		}
+		delete(visiting, key)
+		layer[key] = l
+		return l
+	}
+
+	rows := map[string]map[int]int{}
+	var projects []string
+	layers := 1
+	for _, node := range g.sortedNodes() {
+		l := depth(node.Key, map[string]bool{})
+		layers = max(layers, l+1)
+		if rows[node.Project] == nil {
+			rows[node.Project] = map[int]int{}
+			projects = append(projects, node.Project)
+		}
+		rows[node.Project][l]++
+	}
+
+	positions = map[string]position{}
+	y := float64(margin)
+	for _, project := range projects {
+		bandRows := 0
+		for _, count := range rows[project] {
+			bandRows = max(bandRows, count)
+		}
+		band := svgBand{Project: project, Y: y}
+		used := map[int]int{}
+		for _, node := range g.sortedNodes() {
+			if node.Project != project {
+				continue
+			}
+			l := layer[node.Key]
+			positions[node.Key] = position{
+				x: margin + curveOffset + float64(l)*(nodeWidth+layerGap),
+				y: y + bandPadding + float64(used[l])*(nodeHeight+rowGap),
+			}
+			used[l]++
+		}
+		band.Height = 2*bandPadding + float64(bandRows)*(nodeHeight+rowGap) - rowGap
+		bands = append(bands, band)
+		y += band.Height + rowGap
+	}
+	width = 2*margin + curveOffset + bandPadding + float64(layers)*(nodeWidth+layerGap) - layerGap
+	return positions, bands, width, y - rowGap + margin
+}
+
+type svgBand struct {
+	Project string
+	Y       float64
+	Height  float64
+}
+
+// WriteSVG draws the graph as an SVG document: one band per project, the issues filled with
+// their color and the links as arrows, blocking ones solid, the critical path in red and the
+// cycles in orange.
+func (g *Graph) WriteSVG(w io.Writer, analysis *Analysis) {
+	positions, bands, width, height := g.layout(analysis)
+	critical, cycle := highlights(analysis)
+
+	fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" viewBox="0 0 %.0f %.0f">`+"\n",
+		width, height, width, height)
+	fmt.Fprintf(w, `<rect x="0" y="0" width="%.0f" height="%.0f" fill="#FFFFFF"/>`+"\n", width, height)
+	for _, band := range bands {
+		fmt.Fprintf(w, `<rect x="%d" y="%.0f" width="%.0f" height="%.0f" fill="#F5F5F5" stroke="#BBBBBB" stroke-dasharray="4 4"/>`+"\n",
+			margin, band.Y, width-2*margin, band.Height)
+		fmt.Fprintf(w, `<text x="%d" y="%.0f" font-family="sans-serif" font-size="11" fill="#555555">%s</text>`+"\n",
+			margin+4, band.Y+14, html.EscapeString(band.Project)) //nolint:mnd
+	}
+
+	edges := append([]Edge(nil), g.Edges...)
+	// Highlighted links are drawn last, on top of the others.
+	rank := func(edge Edge) int {
+		key := [2]string{edge.From, edge.To}
+		switch {
+		case critical[key]:
+			return 2 //nolint:mnd
+		case cycle[key]:
+			return 1
+		default:
+			return 0
+		}
+	}
+	sort.SliceStable(edges, func(i, j int) bool { return rank(edges[i]) < rank(edges[j]) })
+	for _, edge := range edges {
+		from, to := positions[edge.From], positions[edge.To]
+		x1, y1 := from.x+nodeWidth, from.y+nodeHeight/2
+		x2, y2 := to.x-arrowSize, to.y+nodeHeight/2
+		c1 := x1 + curveOffset
+		if to.x <= from.x {
+			// Links back to the same or an earlier layer loop around the left side.
+			x1 = from.x
+			c1 = x1 - curveOffset
+		}
+		color, strokeWidth, dash := "#333333", 1.2, ""
+		if !edge.Blocking {
+			color, dash = "#888888", ` stroke-dasharray="5 3"`
+		}
+		switch rank(edge) {
+		case 2: //nolint:mnd
+			color, strokeWidth = criticalColor, 2.5
+		case 1:
+			color, strokeWidth = cycleColor, 2.5
+		}
+		fmt.Fprintf(w, `<path d="M %.1f %.1f C %.1f %.1f %.1f %.1f %.1f %.1f" fill="none" stroke="%s" stroke-width="%.1f"%s/>`+"\n",
+			x1, y1, c1, y1, x2-curveOffset, y2, x2, y2, color, strokeWidth, dash)
+		fmt.Fprintf(w, `<path d="M %.1f %.1f L %.1f %.1f L %.1f %.1f Z" fill="%s"/>`+"\n",
+			x2, y2-arrowSize/2, x2+arrowSize, y2, x2, y2+arrowSize/2, color)
+		if !edge.Blocking {
+			fmt.Fprintf(w, `<text x="%.1f" y="%.1f" font-family="sans-serif" font-size="8" fill="#888888" text-anchor="middle">%s</text>`+"\n",
+				(x1+x2)/2, (y1+y2)/2-3, html.EscapeString(edge.Label)) //nolint:mnd
+		}
+	}
+
+	for _, node := range g.sortedNodes() {
+		p := positions[node.Key]
+		stroke, strokeWidth, dash, text := "#333333", 1.0, "", "#000000"
+		switch {
+		case node.Done:
+			stroke, dash, text = "#777777", ` stroke-dasharray="4 3"`, "#777777"
+		case node.InProgress:
+			strokeWidth = 2.5
+		}
+		fmt.Fprintf(w, `<rect x="%.0f" y="%.0f" width="%d" height="%d" rx="6" ry="6" fill="%s" stroke="%s" stroke-width="%.1f"%s/>`+"\n",
+			p.x, p.y, nodeWidth, nodeHeight, fillColor(node.Color), stroke, strokeWidth, dash)
+		lines := []struct {
+			text   string
+			size   int
+			weight string
+		}{
+			{node.Key, 11, "bold"}, //nolint:mnd
+			{truncate(node.Summary, svgSummaryLength), 9, "normal"}, //nolint:mnd
+			{node.Status, 9, "normal"},                              //nolint:mnd
+		}
+		for i, line := range lines {
+			fmt.Fprintf(w, `<text x="%.0f" y="%.0f" font-family="sans-serif" font-size="%d" font-weight="%s" fill="%s">%s</text>`+"\n",
+				p.x+8, p.y+16+float64(i)*15, line.size, line.weight, text, html.EscapeString(line.text)) //nolint:mnd
+		}
+	}
+	fmt.Fprintln(w, "</svg>")
+}
+
+// fileWriter returns the canvas writer of the format given by the extension of the file name:
+// .svg, .pdf, .png or .jpg.
+func fileWriter(filename string) (canvas.Writer, error) {
+	var writer canvas.Writer
+	switch ext := strings.ToLower(filepath.Ext(filename)); ext {
+	case ".svg":
+		writer = func(w io.Writer, c *canvas.Canvas) error {
+			r := svg.New(w, c.W, c.H, nil)
+			c.RenderTo(r)
+			return r.Close()
+		}
+	case ".pdf":
+		writer = func(w io.Writer, c *canvas.Canvas) error {
+			r := pdf.New(w, c.W, c.H, nil)
+			c.RenderTo(r)
+			return r.Close()
+		}
+	case ".png":
+		writer = func(w io.Writer, c *canvas.Canvas) error {
+			return png.Encode(w, rasterizer.Draw(c, canvas.DPI(dpi), canvas.DefaultColorSpace))
+		}
+	case ".jpg", ".jpeg":
+		writer = func(w io.Writer, c *canvas.Canvas) error {
+			img := rasterizer.Draw(c, canvas.DPI(dpi), canvas.DefaultColorSpace)
+			return jpeg.Encode(w, img, &jpeg.Options{Quality: jpegQuality})
+		}
+	default:
+		return nil, fmt.Errorf("unknown graph file extension %q, use .svg, .pdf, .png or .jpg", ext)
+	}
+	return writer, nil
+}
+
+// RenderFile draws the graph with WriteSVG and writes it through canvas, in the format given by
+// the extension of the file name.
+func (g *Graph) RenderFile(filename string, analysis *Analysis) error {
+	writer, err := fileWriter(filename)
+	if err != nil {
+		return err
+	}
+	var source bytes.Buffer
+	g.WriteSVG(&source, analysis)
+	c, err := canvas.ParseSVG(&source)
+	if err != nil {
+		return fmt.Errorf("parse SVG: %w", err)
+	}
+	return c.WriteFile(filename, writer)
+}
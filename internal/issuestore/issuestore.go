@@ -0,0 +1,175 @@
+package issuestore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+	"github.com/edcdavid/jira-helper/internal/jirahelper"
+)
+
+const maxIssuesRetrieved = 50
+
+// expand is requested on every fetch, so the stored issues have the changelog used to date
+// status summaries.
+const expand = "changelog"
+
+// Store is the on-disk copy of the issues of a JQL query, kept up to date by webhooks.
+type Store struct {
+	mu sync.Mutex
+	// saveMu orders the saves, so the file always ends with the latest state.
+	saveMu sync.Mutex
+
+	Query    string                `json:"query"`
+	SyncedAt time.Time             `json:"syncedAt"`
+	Updated  time.Time             `json:"updated"`
+	Issues   map[string]jira.Issue `json:"issues"`
+}
+
+// Load reads the store at path. A missing file, or a file built for another query, gives an
+// empty store for query that needs a Sync.
+func Load(path, query string) (*Store, error) {
+	store := &Store{Query: query, Issues: map[string]jira.Issue{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	loaded := &Store{}
+	if err := json.Unmarshal(data, loaded); err != nil {
+		return nil, err
+	}
+	if loaded.Query != query || loaded.Issues == nil {
+		return store, nil
+	}
+	return loaded, nil
+}
+
+// LoadIssues returns the issues of the store at path, sorted by key. It fails when the store
+// is missing or was built for another query.
+func LoadIssues(path, query string) ([]jira.Issue, *Store, error) {
+	store, err := Load(path, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	if store.Updated.IsZero() {
+		return nil, nil, fmt.Errorf("no issue store for this query in %s, run the listen command first", path)
+	}
+	return store.List(), store, nil
+}
+
+// Save writes the store atomically, so a reader never sees a partial file.
+func (s *Store) Save(path string) error {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+	s.mu.Lock()
+	data, err := json.Marshal(s)
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Sync replaces the stored issues with the result of the query.
+func (s *Store) Sync(ctx context.Context, client *jira.Client) error {
+	issues, err := jirahelper.FetchAllIssuesExpanded(ctx, client, s.Query, maxIssuesRetrieved, expand)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Issues = make(map[string]jira.Issue, len(issues))
+	for _, issue := range issues {
+		s.Issues[issue.Key] = issue
+	}
+	s.SyncedAt = time.Now()
+	s.Updated = s.SyncedAt
+	return nil
+}
+
+// LastSync returns the time of the last full sync, zero when the store was never synced.
+func (s *Store) LastSync() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.SyncedAt
+}
+
+// List returns the stored issues sorted by key.
+func (s *Store) List() []jira.Issue {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	issues := make([]jira.Issue, 0, len(s.Issues))
+	for _, issue := range s.Issues {
+		issues = append(issues, issue)
+	}
+	sort.Slice(issues, func(i, j int) bool {
+		return issues[i].Key < issues[j].Key
+	})
+	return issues
+}
+
+// Get returns a stored issue.
+func (s *Store) Get(key string) (jira.Issue, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	issue, ok := s.Issues[key]
+	return issue, ok
+}
+
+// Put adds or replaces an issue.
+func (s *Store) Put(issue *jira.Issue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Issues[issue.Key] = *issue
+	s.Updated = time.Now()
+}
+
+// Delete removes an issue. It returns false when the issue was not stored.
+func (s *Store) Delete(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.Issues[key]; !ok {
+		return false
+	}
+	delete(s.Issues, key)
+	s.Updated = time.Now()
+	return true
+}
+
+// FetchIfMatching returns the issue with its changelog when it matches the query of the store,
+// and false when it does not.
+func (s *Store) FetchIfMatching(ctx context.Context, client *jira.Client, key string) (*jira.Issue, bool, error) {
+	jql := fmt.Sprintf("issue = %s and (%s)", key, s.Query)
+	issues, _, err := client.Issue.Search(ctx, jql, &jira.SearchOptions{MaxResults: 1, Expand: expand})
+	if err != nil {
+		return nil, false, err
+	}
+	if len(issues) == 0 || issues[0].Key != key {
+		return nil, false, nil
+	}
+	return &issues[0], true, nil
+}
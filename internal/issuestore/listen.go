@@ -0,0 +1,121 @@
+package issuestore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+)
+
+const (
+	readHeaderTimeout = 10 * time.Second
+	shutdownTimeout   = 30 * time.Second
+)
+
+// ListenOptions configures Listen.
+type ListenOptions struct {
+	Listen string
+	// Path is the URL path of the webhook endpoint.
+	Path string
+	// Resync is the interval of the full query used to catch missed webhooks, 0 to disable.
+	Resync time.Duration
+}
+
+// Listen keeps the store of the handler up to date from webhooks until ctx is done. The store
+// is synced with the full query first when it is empty.
+func Listen(ctx context.Context, client *jira.Client, handler *Handler, opts *ListenOptions) error {
+	if handler.Store.LastSync().IsZero() {
+		if err := resync(ctx, client, handler); err != nil {
+			return err
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("POST "+opts.Path, handler)
+	srv := &http.Server{
+		Addr:              opts.Listen,
+		Handler:           mux,
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	resyncCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if opts.Resync > 0 {
+		go func() {
+			ticker := time.NewTicker(opts.Resync)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-resyncCtx.Done():
+					return
+				case <-ticker.C:
+				}
+				if err := resync(resyncCtx, client, handler); err != nil {
+					log.Printf("Resync failed, keeping the store, err:%v", err)
+				}
+			}
+		}()
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		log.Printf("Listening for webhooks on %s%s", opts.Listen, opts.Path)
+		errs <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer shutdownCancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errs; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func resync(ctx context.Context, client *jira.Client, handler *Handler) error {
+	if err := handler.Store.Sync(ctx, client); err != nil {
+		return fmt.Errorf("cannot sync the issue store, err:%w", err)
+	}
+	log.Printf("Issue store synced, %d issues", len(handler.Store.List()))
+	return handler.Store.Save(handler.StorePath)
+}
+
+// Replay applies recorded webhook payload files to the store of the handler, in order, then
+// saves it.
+func Replay(ctx context.Context, handler *Handler, files []string, out io.Writer) error {
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		payload := &Payload{}
+		if err := json.Unmarshal(data, payload); err != nil {
+			return fmt.Errorf("invalid webhook payload %s, err:%w", file, err)
+		}
+		changed, err := handler.Apply(ctx, payload)
+		if err != nil {
+			return fmt.Errorf("cannot apply %s, err:%w", file, err)
+		}
+		key := ""
+		if payload.Issue != nil {
+			key = payload.Issue.Key
+		}
+		fmt.Fprintf(out, "%s: %s %s changed=%t\n", file, payload.WebhookEvent, key, changed)
+	}
+	return handler.Store.Save(handler.StorePath)
+}
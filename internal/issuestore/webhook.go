@@ -0,0 +1,172 @@
+package issuestore
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+	"github.com/edcdavid/jira-helper/internal/jirahelper"
+)
+
+// Webhook events applied to the store. Other events are acknowledged and ignored.
+const (
+	EventIssueCreated = "jira:issue_created"
+	EventIssueUpdated = "jira:issue_updated"
+	EventIssueDeleted = "jira:issue_deleted"
+)
+
+const maxPayloadSize = 10 << 20
+
+// Payload is the body of a Jira issue webhook.
+type Payload struct {
+	Timestamp    int64       `json:"timestamp"`
+	WebhookEvent string      `json:"webhookEvent"`
+	User         *jira.User  `json:"user,omitempty"`
+	Issue        *jira.Issue `json:"issue"`
+	Changelog    *struct {
+		ID    string                `json:"id"`
+		Items []jira.ChangelogItems `json:"items"`
+	} `json:"changelog,omitempty"`
+}
+
+// Handler applies issue webhooks to a store and saves it after each change.
+type Handler struct {
+	Store     *Store
+	StorePath string
+	// Secret, when set, must be given as the secret query parameter, or be the key of the
+	// HMAC-SHA256 signature sent by Jira Cloud in the X-Hub-Signature header.
+	Secret string
+	// Client checks that created and updated issues match the query of the store, and fetches
+	// them with their full changelog. Without it, payload issues are stored as is.
+	Client *jira.Client
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxPayloadSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !h.authorized(r, body) {
+		http.Error(w, "invalid webhook secret", http.StatusUnauthorized)
+		return
+	}
+
+	payload := &Payload{}
+	if err := json.Unmarshal(body, payload); err != nil {
+		http.Error(w, "invalid webhook payload: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	changed, err := h.Apply(r.Context(), payload)
+	if err != nil {
+		log.Printf("Cannot apply %s webhook, err:%v", payload.WebhookEvent, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if changed {
+		if err := h.Store.Save(h.StorePath); err != nil {
+			log.Printf("Cannot save the issue store, err:%v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) authorized(r *http.Request, body []byte) bool {
+	if h.Secret == "" {
+		return true
+	}
+	if secret := r.URL.Query().Get("secret"); secret != "" {
+		return subtle.ConstantTimeCompare([]byte(secret), []byte(h.Secret)) == 1
+	}
+	signature, ok := strings.CutPrefix(r.Header.Get("X-Hub-Signature"), "sha256=")
+	if !ok {
+		return false
+	}
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(h.Secret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// Apply updates the store with one webhook. It returns whether the store changed.
+func (h *Handler) Apply(ctx context.Context, payload *Payload) (bool, error) {
+	if payload.Issue == nil || payload.Issue.Key == "" {
+		if strings.HasPrefix(payload.WebhookEvent, "jira:issue_") {
+			return false, errors.New("webhook without issue")
+		}
+		return false, nil
+	}
+	key := payload.Issue.Key
+
+	switch payload.WebhookEvent {
+	case EventIssueDeleted:
+		deleted := h.Store.Delete(key)
+		log.Printf("%s deleted, removed from the store: %t", key, deleted)
+		return deleted, nil
+	case EventIssueCreated, EventIssueUpdated:
+	default:
+		return false, nil
+	}
+
+	if h.Client == nil {
+		h.Store.Put(h.withChangelog(payload))
+		log.Printf("%s stored from %s", key, payload.WebhookEvent)
+		return true, nil
+	}
+
+	issue, matching, err := h.Store.FetchIfMatching(ctx, h.Client, key)
+	if err != nil {
+		return false, fmt.Errorf("cannot fetch %s, err:%w", key, err)
+	}
+	if !matching {
+		deleted := h.Store.Delete(key)
+		log.Printf("%s does not match the query, removed from the store: %t", key, deleted)
+		return deleted, nil
+	}
+	h.Store.Put(issue)
+	log.Printf("%s stored from %s", key, payload.WebhookEvent)
+	return true, nil
+}
+
+// withChangelog returns the payload issue with the changelog of the stored issue, extended
+// with the change of the webhook, as a payload only carries its own change.
+func (h *Handler) withChangelog(payload *Payload) *jira.Issue {
+	issue := *payload.Issue
+	if previous, ok := h.Store.Get(issue.Key); ok && previous.Changelog != nil {
+		changelog := *previous.Changelog
+		changelog.Histories = append([]jira.ChangelogHistory{}, changelog.Histories...)
+		issue.Changelog = &changelog
+	}
+	if payload.Changelog == nil || len(payload.Changelog.Items) == 0 {
+		return &issue
+	}
+	if issue.Changelog == nil {
+		issue.Changelog = &jira.Changelog{}
+	}
+	history := jira.ChangelogHistory{
+		Id:      payload.Changelog.ID,
+		Created: time.UnixMilli(payload.Timestamp).Format(jirahelper.TimeLayout),
+		Items:   payload.Changelog.Items,
+	}
+	if payload.User != nil {
+		history.Author = *payload.User
+	}
+	issue.Changelog.Histories = append(issue.Changelog.Histories, history)
+	return &issue
+}
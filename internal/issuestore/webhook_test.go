@@ -0,0 +1,169 @@
+package issuestore
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+const testSecret = "s3cret"
+
+func sign(body []byte) string {
+	mac := hmac.New(sha256.New, []byte(testSecret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func newTestHandler(t *testing.T) *Handler {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "store.json")
+	store, err := Load(path, "project = CNF")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Handler{Store: store, StorePath: path, Secret: testSecret}
+}
+
+func readPayload(t *testing.T, name string) []byte {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", "webhooks", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+// post sends a webhook to the handler, with the query string given, and returns the status.
+func post(h *Handler, query string, body []byte, header http.Header) int {
+	r := httptest.NewRequest(http.MethodPost, "/webhook"+query, bytes.NewReader(body))
+	for name, values := range header {
+		r.Header[name] = values
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w.Code
+}
+
+func storedKeys(s *Store) []string {
+	var keys []string
+	for _, issue := range s.List() {
+		keys = append(keys, issue.Key)
+	}
+	return keys
+}
+
+func TestHandlerReplay(t *testing.T) {
+	h := newTestHandler(t)
+	steps := []struct {
+		payload string
+		keys    []string
+		check   func(t *testing.T, s *Store)
+	}{
+		{
+			payload: "01_issue_created.json",
+			keys:    []string{"CNF-101"},
+			check: func(t *testing.T, s *Store) {
+				issue, _ := s.Get("CNF-101")
+				if issue.Fields.Status.Name != "New" {
+					t.Errorf("CNF-101 status = %q, want New", issue.Fields.Status.Name)
+				}
+			},
+		},
+		{
+			payload: "02_issue_updated.json",
+			keys:    []string{"CNF-101"},
+			check: func(t *testing.T, s *Store) {
+				issue, _ := s.Get("CNF-101")
+				if issue.Fields.Status.Name != "In Progress" {
+					t.Errorf("CNF-101 status = %q, want In Progress", issue.Fields.Status.Name)
+				}
+				if issue.Changelog == nil || len(issue.Changelog.Histories) != 1 {
+					t.Fatalf("CNF-101 changelog = %+v, want the change of the webhook", issue.Changelog)
+				}
+				history := issue.Changelog.Histories[0]
+				if history.Author.DisplayName != "Jane Doe" || len(history.Items) != 3 {
+					t.Errorf("CNF-101 history = %+v", history)
+				}
+			},
+		},
+		{
+			// Comment events are acknowledged and leave the store as is.
+			payload: "03_comment_created.json",
+			keys:    []string{"CNF-101"},
+		},
+		{
+			payload: "04_issue_created.json",
+			keys:    []string{"CNF-101", "CNF-102"},
+		},
+		{
+			payload: "05_issue_deleted.json",
+			keys:    []string{"CNF-101"},
+		},
+	}
+	for _, step := range steps {
+		body := readPayload(t, step.payload)
+		if code := post(h, "", body, http.Header{"X-Hub-Signature": {sign(body)}}); code != http.StatusNoContent {
+			t.Fatalf("%s: status %d, want %d", step.payload, code, http.StatusNoContent)
+		}
+		if keys := storedKeys(h.Store); !slices.Equal(keys, step.keys) {
+			t.Errorf("%s: stored %v, want %v", step.payload, keys, step.keys)
+		}
+		if step.check != nil {
+			step.check(t, h.Store)
+		}
+	}
+
+	// The saved store has the state of the last webhook.
+	saved, err := Load(h.StorePath, h.Store.Query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys := storedKeys(saved); !slices.Equal(keys, []string{"CNF-101"}) {
+		t.Errorf("saved store has %v, want [CNF-101]", keys)
+	}
+}
+
+func TestHandlerSecret(t *testing.T) {
+	body := readPayload(t, "01_issue_created.json")
+	tests := []struct {
+		name   string
+		query  string
+		header http.Header
+		want   int
+	}{
+		{name: "hmac signature", header: http.Header{"X-Hub-Signature": {sign(body)}}, want: http.StatusNoContent},
+		{name: "secret query parameter", query: "?secret=" + testSecret, want: http.StatusNoContent},
+		{name: "missing signature", want: http.StatusUnauthorized},
+		{name: "signature of another body", header: http.Header{"X-Hub-Signature": {sign([]byte("{}"))}},
+			want: http.StatusUnauthorized},
+		{name: "signature not hex", header: http.Header{"X-Hub-Signature": {"sha256=zz"}},
+			want: http.StatusUnauthorized},
+		{name: "signature without algorithm", header: http.Header{"X-Hub-Signature": {sign(body)[len("sha256="):]}},
+			want: http.StatusUnauthorized},
+		{name: "wrong secret query parameter", query: "?secret=wrong", want: http.StatusUnauthorized},
+		{name: "wrong secret query parameter with a valid signature", query: "?secret=wrong",
+			header: http.Header{"X-Hub-Signature": {sign(body)}}, want: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(t)
+			if code := post(h, tt.query, body, tt.header); code != tt.want {
+				t.Fatalf("status %d, want %d", code, tt.want)
+			}
+			keys := storedKeys(h.Store)
+			if tt.want == http.StatusNoContent && !slices.Equal(keys, []string{"CNF-101"}) {
+				t.Errorf("stored %v, want [CNF-101]", keys)
+			}
+			if tt.want != http.StatusNoContent && len(keys) != 0 {
+				t.Errorf("rejected webhook stored %v", keys)
+			}
+		})
+	}
+}
@@ -2,18 +2,58 @@ package jirahelper
 
 import (
 	"context"
+	"net/http"
 	"os"
+	"strconv"
 	"time"
 
 	jira "github.com/andygrunwald/go-jira/v2/cloud"
 	"github.com/schollz/progressbar/v3"
 )
 
+// Custom fields holding the health color and the status summary of an issue.
+const (
+	ColorField         = "customfield_12320845"
+	StatusSummaryField = "customfield_12320841"
+)
+
+// TimeLayout is the layout of the date times returned by the Jira REST API.
+const TimeLayout = "2006-01-02T15:04:05.000-0700"
+
+type patTransport struct {
+	Token string
+}
+
+func (t *patTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.Header.Set("Authorization", "Bearer "+t.Token)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// NewClient returns a Jira client authenticated with a Personal Access Token.
+func NewClient(jiraURL, personalAccessToken string) (*jira.Client, error) {
+	httpClient := &http.Client{
+		Transport: &patTransport{Token: personalAccessToken},
+	}
+	return jira.NewClient(jiraURL, httpClient)
+}
+
 func FetchAllIssues(ctx context.Context, client *jira.Client, jql string, maxResults int) ([]jira.Issue, error) {
+	return FetchAllIssuesExpanded(ctx, client, jql, maxResults, "")
+}
+
+// FetchAllIssuesExpanded is FetchAllIssues with the expand parameter of the search, for
+// example "changelog".
+func FetchAllIssuesExpanded(ctx context.Context, client *jira.Client, jql string, maxResults int, expand string) ([]jira.Issue, error) {
+	return FetchAllIssuesFields(ctx, client, jql, maxResults, expand, nil)
+}
+
+// FetchAllIssuesFields is FetchAllIssuesExpanded returning the given fields rather than the
+// default ones, for example "*navigable" and "comment", which the search leaves out.
+func FetchAllIssuesFields(ctx context.Context, client *jira.Client, jql string, maxResults int, expand string, fields []string) ([]jira.Issue, error) {
 	var allIssues []jira.Issue
 
 	// Step 1: Fetch initial page to get total
-	options := &jira.SearchOptions{StartAt: 0, MaxResults: 0}
+	options := &jira.SearchOptions{StartAt: 0, MaxResults: 0, Expand: expand, Fields: fields}
 	result, resp, err := client.Issue.Search(ctx, jql, options)
 	if err != nil {
 		return nil, err
@@ -32,7 +72,7 @@ func FetchAllIssues(ctx context.Context, client *jira.Client, jql string, maxRes
 
 	// Step 3: Fetch remaining pages
 	for startAt := len(result); startAt < total; startAt += maxResults {
-		options := &jira.SearchOptions{StartAt: startAt, MaxResults: maxResults}
+		options := &jira.SearchOptions{StartAt: startAt, MaxResults: maxResults, Expand: expand, Fields: fields}
 		page, _, err := client.Issue.Search(ctx, jql, options)
 		if err != nil {
 			return nil, err
@@ -44,3 +84,46 @@ func FetchAllIssues(ctx context.Context, client *jira.Client, jql string, maxRes
 
 	return allIssues, nil
 }
+
+// FieldIDsByName maps the names of the fields of the server to their ids, for example
+// "Story Points" to "customfield_12310243".
+func FieldIDsByName(ctx context.Context, client *jira.Client) (map[string]string, error) {
+	fields, _, err := client.Field.GetList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ids := map[string]string{}
+	for i := range fields {
+		ids[fields[i].Name] = fields[i].ID
+	}
+	return ids, nil
+}
+
+// WithRetry calls request until it succeeds, retrying up to retries times with an
+// exponential backoff when the server is rate limiting (429) or failing (5xx). A Retry-After
+// header sent by the server takes precedence over the backoff.
+func WithRetry(ctx context.Context, retries int, request func() (*jira.Response, error)) error {
+	backoff := time.Second
+	for attempt := 0; ; attempt++ {
+		resp, err := request()
+		if err == nil {
+			return nil
+		}
+		if attempt >= retries || resp == nil ||
+			resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < http.StatusInternalServerError {
+			return jira.NewJiraError(resp, err)
+		}
+		resp.Body.Close()
+
+		wait := backoff
+		if seconds, convErr := strconv.Atoi(resp.Header.Get("Retry-After")); convErr == nil {
+			wait = time.Duration(seconds) * time.Second
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		backoff *= 2
+	}
+}
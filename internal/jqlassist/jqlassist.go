@@ -0,0 +1,199 @@
+package jqlassist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+	"github.com/edcdavid/jira-helper/internal/ollamahelper"
+	"github.com/edcdavid/jira-helper/internal/prompts"
+)
+
+var (
+	codeFenceRe = regexp.MustCompile("(?s)```(?:jql|sql)?\\s*(.*?)```")
+	wordRe      = regexp.MustCompile(`[a-z0-9]+`)
+)
+
+// ServerContext holds the names the model may use in the JQL it proposes.
+type ServerContext struct {
+	Fields   []string
+	Projects []string
+	Statuses []string
+}
+
+// PromptData is the data made available to the JQL prompt template.
+type PromptData struct {
+	Question string
+	Today    string
+	ServerContext
+	// PreviousJQL and Errors are set when a proposal was rejected by the server.
+	PreviousJQL string
+	Errors      []string
+}
+
+// FetchServerContext reads the field, project and status names from the server and keeps
+// the maxNames of each that share most words with the question, so the prompt stays small
+// on large instances.
+func FetchServerContext(ctx context.Context, client *jira.Client, question string, maxNames int) (*ServerContext, error) {
+	fields, _, err := client.Field.GetList(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get fields: %w", err)
+	}
+	projects, _, err := client.Project.GetAll(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("get projects: %w", err)
+	}
+	statuses, _, err := client.Status.GetAllStatuses(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get statuses: %w", err)
+	}
+
+	var fieldNames, projectNames, statusNames []string
+	for i := range fields {
+		fieldNames = append(fieldNames, fields[i].Name)
+	}
+	for _, project := range *projects {
+		projectNames = append(projectNames, fmt.Sprintf("%s (key %s)", project.Name, project.Key))
+	}
+	for i := range statuses {
+		statusNames = append(statusNames, statuses[i].Name)
+	}
+
+	return &ServerContext{
+		Fields:   mostRelevant(fieldNames, question, maxNames),
+		Projects: mostRelevant(projectNames, question, maxNames),
+		Statuses: mostRelevant(statusNames, question, maxNames),
+	}, nil
+}
+
+// mostRelevant deduplicates names and returns at most limit of them, preferring the ones
+// sharing words with the question.
+func mostRelevant(names []string, question string, limit int) []string {
+	questionWords := map[string]bool{}
+	for _, word := range wordRe.FindAllString(strings.ToLower(question), -1) {
+		questionWords[word] = true
+	}
+
+	seen := map[string]bool{}
+	scores := map[string]int{}
+	var unique []string
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		unique = append(unique, name)
+		for _, word := range wordRe.FindAllString(strings.ToLower(name), -1) {
+			if questionWords[word] {
+				scores[name]++
+			}
+		}
+	}
+
+	sort.SliceStable(unique, func(i, j int) bool {
+		return scores[unique[i]] > scores[unique[j]]
+	})
+	if len(unique) > limit {
+		unique = unique[:limit]
+	}
+	sort.Strings(unique)
+	return unique
+}
+
+// Propose asks the model for a JQL query answering the question.
+func Propose(ctx context.Context, ollamaModel, promptOverride string, data *PromptData) (string, error) {
+	data.Today = time.Now().Format(time.DateOnly)
+	prompt, err := prompts.Render(prompts.JQL, promptOverride, data)
+	if err != nil {
+		return "", err
+	}
+	answer, err := ollamahelper.Chat(ctx, ollamaModel, "", prompt)
+	if err != nil {
+		return "", err
+	}
+	if match := codeFenceRe.FindStringSubmatch(answer); match != nil {
+		answer = match[1]
+	}
+	return strings.Join(strings.Fields(answer), " "), nil
+}
+
+type parseRequest struct {
+	Queries []string `json:"queries"`
+}
+
+type parseResponse struct {
+	Queries []struct {
+		Query  string   `json:"query"`
+		Errors []string `json:"errors"`
+	} `json:"queries"`
+}
+
+// Validate checks the query with the server's JQL parse endpoint and returns the errors it
+// reports. Servers without that endpoint (Jira Data Center) are asked to run the query with
+// strict validation and no results instead.
+func Validate(ctx context.Context, client *jira.Client, jql string) ([]string, error) {
+	req, err := client.NewRequest(ctx, http.MethodPost, "rest/api/2/jql/parse?validation=strict",
+		&parseRequest{Queries: []string{jql}})
+	if err != nil {
+		return nil, err
+	}
+	parsed := &parseResponse{}
+	resp, err := client.Do(req, parsed)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return validateWithSearch(ctx, client, jql)
+	}
+	if err != nil {
+		return nil, jira.NewJiraError(resp, err)
+	}
+	if len(parsed.Queries) == 0 {
+		return nil, errors.New("empty JQL parse response")
+	}
+	return parsed.Queries[0].Errors, nil
+}
+
+func validateWithSearch(ctx context.Context, client *jira.Client, jql string) ([]string, error) {
+	_, resp, err := client.Issue.Search(ctx, jql, &jira.SearchOptions{MaxResults: 1, ValidateQuery: "strict"})
+	if err == nil {
+		return nil, nil
+	}
+	if resp != nil && resp.StatusCode == http.StatusBadRequest {
+		var jiraErr *jira.Error
+		if errors.As(err, &jiraErr) {
+			return jiraErr.ErrorMessages, nil
+		}
+		return []string{err.Error()}, nil
+	}
+	return nil, err
+}
+
+// Ask proposes a JQL query for the question and validates it against the server. A rejected
+// proposal is sent back to the model with the server errors, up to retries times. The last
+// proposal is returned with its validation errors, if any.
+func Ask(ctx context.Context, client *jira.Client, ollamaModel, promptOverride, question string,
+	maxNames, retries int) (jql string, validationErrors []string, err error) {
+	serverContext, err := FetchServerContext(ctx, client, question, maxNames)
+	if err != nil {
+		return "", nil, err
+	}
+
+	data := &PromptData{Question: question, ServerContext: *serverContext}
+	for attempt := 0; attempt <= retries; attempt++ {
+		jql, err = Propose(ctx, ollamaModel, promptOverride, data)
+		if err != nil {
+			return "", nil, err
+		}
+		validationErrors, err = Validate(ctx, client, jql)
+		if err != nil || len(validationErrors) == 0 {
+			return jql, validationErrors, err
+		}
+		data.PreviousJQL = jql
+		data.Errors = validationErrors
+	}
+	return jql, validationErrors, nil
+}
@@ -0,0 +1,206 @@
+package nudge
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+	"github.com/edcdavid/jira-helper/internal/jirahelper"
+	"github.com/edcdavid/jira-helper/internal/reports"
+	"github.com/edcdavid/jira-helper/internal/timehelper"
+)
+
+const (
+	maxIssuesRetrieved = 50
+
+	// marker ends every reminder, so reminders already posted can be found in the comments.
+	marker = "{color:#999999}_Automatic reminder sent by jira-helper nudge_{color}"
+)
+
+//go:embed templates/nudge.tmpl
+var defaultTemplate string
+
+// Options configures Run.
+type Options struct {
+	JQL string
+	// Threshold is the age from which a status summary is considered stale.
+	Threshold time.Duration
+	// Window is the minimum time between two reminders on the same issue.
+	Window time.Duration
+	// Ledger is the local file recording the reminders posted.
+	Ledger string
+	// TemplateFile replaces the embedded comment template when set.
+	TemplateFile string
+	Interval     time.Duration
+	Retries      int
+	DryRun       bool
+}
+
+// commentData is the data made available to the comment template.
+type commentData struct {
+	Key     string
+	Summary string
+	Mention string
+	Dated   bool
+	AgeDays int
+	Marker  string
+}
+
+// Run posts a reminder to the assignee of the open issues of the query whose status summary
+// is missing or older than the threshold, unless one was posted within the window.
+func Run(ctx context.Context, client *jira.Client, opts *Options, out io.Writer) error { //nolint:funlen
+	tmpl, err := loadTemplate(opts.TemplateFile)
+	if err != nil {
+		return err
+	}
+	ledger, err := loadLedger(opts.Ledger)
+	if err != nil {
+		return err
+	}
+
+	jql := fmt.Sprintf("(%s) and statusCategory != Done", opts.JQL)
+	// The comments are not among the default fields of a search, the reminders already posted
+	// are found in them.
+	issues, err := jirahelper.FetchAllIssuesFields(ctx, client, jql, maxIssuesRetrieved, "changelog",
+		[]string{"*navigable", "comment"})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	posted := 0
+	for i := range issues {
+		issue := &issues[i]
+		date, dated := reports.StatusSummaryDate(issue, now)
+		if dated && now.Sub(date) <= opts.Threshold {
+			continue
+		}
+		if last, ok := lastReminder(issue, ledger); ok && now.Sub(last) < opts.Window {
+			fmt.Fprintf(out, "%s: skipped, reminded %s\n", issue.Key, last.Format(time.DateOnly))
+			continue
+		}
+		mention := mentionOf(issue.Fields.Assignee)
+		if mention == "" {
+			fmt.Fprintf(out, "%s: skipped, no assignee\n", issue.Key)
+			continue
+		}
+
+		var body bytes.Buffer
+		err := tmpl.Execute(&body, commentData{
+			Key:     issue.Key,
+			Summary: issue.Fields.Summary,
+			Mention: mention,
+			Dated:   dated,
+			AgeDays: timehelper.Days(now.Sub(date)),
+			Marker:  marker,
+		})
+		if err != nil {
+			return err
+		}
+
+		if opts.DryRun {
+			fmt.Fprintf(out, "%s: would post:\n%s\n", issue.Key, indent(body.String()))
+			continue
+		}
+		if posted > 0 {
+			time.Sleep(opts.Interval)
+		}
+		err = jirahelper.WithRetry(ctx, opts.Retries, func() (*jira.Response, error) {
+			_, resp, err := client.Issue.AddComment(ctx, issue.Key, &jira.Comment{Body: body.String()})
+			return resp, err
+		})
+		if err != nil {
+			fmt.Fprintf(out, "%s: failed: %v\n", issue.Key, err)
+			continue
+		}
+		posted++
+		ledger[issue.Key] = now
+		if err := saveLedger(opts.Ledger, ledger); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "%s: reminder posted to %s\n", issue.Key, mention)
+	}
+
+	if opts.DryRun {
+		fmt.Fprintln(out, "Dry run, no comment was posted")
+	}
+	return nil
+}
+
+func loadTemplate(path string) (*template.Template, error) {
+	source := defaultTemplate
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		source = string(data)
+	}
+	return template.New("nudge").Parse(source)
+}
+
+// lastReminder returns the time of the last reminder from the ledger, or from a comment
+// holding the marker, which also covers reminders posted from another machine.
+func lastReminder(issue *jira.Issue, ledger map[string]time.Time) (time.Time, bool) {
+	last, ok := ledger[issue.Key]
+	if issue.Fields.Comments == nil {
+		return last, ok
+	}
+	for _, comment := range issue.Fields.Comments.Comments {
+		if !strings.Contains(comment.Body, marker) {
+			continue
+		}
+		if created, err := time.Parse(jirahelper.TimeLayout, comment.Created); err == nil && created.After(last) {
+			last, ok = created, true
+		}
+	}
+	return last, ok
+}
+
+// mentionOf returns the wiki markup mentioning a user: the user name on Jira Data Center and
+// the account id on Jira Cloud.
+func mentionOf(user *jira.User) string {
+	switch {
+	case user == nil:
+		return ""
+	case user.Name != "":
+		return "[~" + user.Name + "]"
+	case user.AccountID != "":
+		return "[~accountid:" + user.AccountID + "]"
+	}
+	return ""
+}
+
+func loadLedger(path string) (map[string]time.Time, error) {
+	ledger := map[string]time.Time{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return ledger, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ledger, json.Unmarshal(data, &ledger)
+}
+
+func saveLedger(path string, ledger map[string]time.Time) error {
+	data, err := json.MarshalIndent(ledger, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600) //nolint:mnd
+}
+
+func indent(text string) string {
+	return "    " + strings.ReplaceAll(strings.TrimSpace(text), "\n", "\n    ")
+}
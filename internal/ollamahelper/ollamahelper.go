@@ -0,0 +1,80 @@
+package ollamahelper
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/ollama/ollama/api"
+)
+
+const aiSeed = 42
+
+var streamOff = false
+
+// Chat sends a single system/user exchange to the given Ollama model and returns the
+// answer without <think> blocks or leading whitespace. An empty system prompt is omitted.
+func Chat(ctx context.Context, ollamaModel, systemPrompt, userPrompt string) (string, error) {
+	client, err := api.ClientFromEnvironment()
+	if err != nil {
+		return "", err
+	}
+
+	messages := []api.Message{}
+	if systemPrompt != "" {
+		messages = append(messages, api.Message{Role: "system", Content: systemPrompt})
+	}
+	messages = append(messages, api.Message{Role: "user", Content: userPrompt})
+
+	chatReq := &api.ChatRequest{
+		Model:    ollamaModel,
+		Messages: messages,
+		Options: map[string]interface{}{
+			"seed": aiSeed,
+		},
+		Stream: &streamOff,
+	}
+
+	var chatResp string
+	err = client.Chat(ctx, chatReq, func(resp api.ChatResponse) error {
+		chatResp = TrimLeadingWhitespaceAndNewlines(RemoveThinkBlocks(resp.Message.Content))
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	log.Println("Model:", ollamaModel)
+	log.Println("Prompt:", userPrompt)
+	log.Println("Model response:", chatResp)
+	log.Println("------------------------------------------")
+	return chatResp, nil
+}
+
+// Embed returns the embedding vectors of inputs computed with the given Ollama embedding model.
+func Embed(ctx context.Context, ollamaModel string, inputs []string) ([][]float32, error) {
+	client, err := api.ClientFromEnvironment()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Embed(ctx, &api.EmbedRequest{Model: ollamaModel, Input: inputs})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Embeddings) != len(inputs) {
+		return nil, fmt.Errorf("model %s returned %d embeddings for %d inputs", ollamaModel, len(resp.Embeddings), len(inputs))
+	}
+	return resp.Embeddings, nil
+}
+
+func TrimLeadingWhitespaceAndNewlines(s string) string {
+	return strings.TrimLeftFunc(s, unicode.IsSpace)
+}
+
+func RemoveThinkBlocks(input string) string {
+	re := regexp.MustCompile(`(?s)<think>.*?</think>`)
+	return re.ReplaceAllString(input, "")
+}
@@ -0,0 +1,57 @@
+package prompts
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"os"
+	"path"
+	"text/template"
+)
+
+// Prompt names carry their version so a wording change is a new file, not an edit of a
+// template that earlier reports were produced with.
+const (
+	FormatStatus = "format_status.v1"
+	JQL          = "jql.v1"
+	Summary      = "summary.v1"
+	SuggestColor = "suggest_color.v1"
+)
+
+// FormatStatusData is the data of the format status prompt, which is sent as the system
+// message while the status summary itself is the user message.
+type FormatStatusData struct {
+	// Year is assumed for status entries written without a year.
+	Year int
+}
+
+//go:embed templates/*.tmpl
+var templatesFS embed.FS
+
+// Render executes the named embedded prompt template with data. When overridePath is not
+// empty, the template is read from that file instead.
+func Render(name, overridePath string, data any) (string, error) {
+	var (
+		source []byte
+		err    error
+	)
+	if overridePath != "" {
+		source, err = os.ReadFile(overridePath)
+	} else {
+		source, err = templatesFS.ReadFile(path.Join("templates", name+".tmpl"))
+	}
+	if err != nil {
+		return "", fmt.Errorf("read prompt %s: %w", name, err)
+	}
+
+	tmpl, err := template.New(name).Parse(string(source))
+	if err != nil {
+		return "", fmt.Errorf("parse prompt %s: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("render prompt %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
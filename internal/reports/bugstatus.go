@@ -0,0 +1,352 @@
+package reports
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+)
+
+const (
+	// DefaultGroupBy is the field the issues of a filter without groupBy are counted by.
+	DefaultGroupBy = "component"
+	// noGroup is the group of the issues without a value for the field.
+	noGroup = "None"
+	// maxInlineIssues is the number of issues of a group above which they are listed in a
+	// collapsed block.
+	maxInlineIssues = 10
+)
+
+// priorityOrder ranks the priorities, the others come after them.
+var priorityOrder = []string{"Blocker", "Critical", "Major", "Normal", "Minor", "Trivial"}
+
+// bugStatusIssue is an issue counted by a bug status filter, with its groups.
+type bugStatusIssue struct {
+	Key      string
+	Summary  string
+	Priority string
+	Assignee string
+	Created  time.Time
+	Groups   []string
+}
+
+// stackColors are the colors of the stacked values, in order of decreasing count.
+var stackColors = []string{
+	blueColor, "#D62728", yellowColor, "#3CB371", "#FF7F0E", "#9467BD", "#8C564B", "#E377C2", "#17BECF", "#7F7F7F",
+}
+
+// countIssues counts the issues per group, and per stack in each group when the count is
+// stacked. The total counts each issue once whatever its number of groups.
+func (c *BugStatusCount) countIssues(issues []jira.Issue, fieldIDs map[string]string) {
+	c.Groups = map[string]int{}
+	if c.StackBy != "" {
+		c.Stacks = map[string]map[string]int{}
+		c.StackTotals = map[string]int{}
+	}
+	for i := range issues {
+		c.Total++
+		var stacks []string
+		if c.StackBy != "" {
+			stacks = groupValues(&issues[i], c.StackBy, fieldIDs)
+			for _, stack := range stacks {
+				c.StackTotals[stack]++
+			}
+		}
+		issue := bugStatusIssue{
+			Key:     issues[i].Key,
+			Summary: issues[i].Fields.Summary,
+			Created: time.Time(issues[i].Fields.Created),
+			Groups:  groupValues(&issues[i], c.GroupBy, fieldIDs),
+		}
+		if issues[i].Fields.Priority != nil {
+			issue.Priority = issues[i].Fields.Priority.Name
+		}
+		if issues[i].Fields.Assignee != nil {
+			issue.Assignee = issues[i].Fields.Assignee.DisplayName
+		}
+		c.issues = append(c.issues, issue)
+		for _, group := range issue.Groups {
+			c.Groups[group]++
+			if c.StackBy == "" {
+				continue
+			}
+			if c.Stacks[group] == nil {
+				c.Stacks[group] = map[string]int{}
+			}
+			for _, stack := range stacks {
+				c.Stacks[group][stack]++
+			}
+		}
+	}
+}
+
+// groupValues returns the distinct values of a field of the issue, or noGroup when it has none.
+func groupValues(issue *jira.Issue, field string, fieldIDs map[string]string) []string {
+	var values []string
+	for _, value := range fieldValues(issue, field, fieldIDs) {
+		if !slices.Contains(values, value) {
+			values = append(values, value)
+		}
+	}
+	if len(values) == 0 {
+		return []string{noGroup}
+	}
+	return values
+}
+
+// stackOrder returns the stacked values by decreasing count, noGroup last.
+func (c *BugStatusCount) stackOrder() []string {
+	stacks := make([]string, 0, len(c.StackTotals))
+	for stack := range c.StackTotals {
+		stacks = append(stacks, stack)
+	}
+	sort.Slice(stacks, func(i, j int) bool {
+		if (stacks[i] == noGroup) != (stacks[j] == noGroup) {
+			return stacks[j] == noGroup
+		}
+		if c.StackTotals[stacks[i]] != c.StackTotals[stacks[j]] {
+			return c.StackTotals[stacks[i]] > c.StackTotals[stacks[j]]
+		}
+		return stacks[i] < stacks[j]
+	})
+	return stacks
+}
+
+// caption names the fields of the chart, with the colors of the stacked values. It is empty for
+// the default count per component, whose chart is self explanatory.
+func (c *BugStatusCount) caption() string {
+	if c.StackBy == "" {
+		if c.GroupBy == DefaultGroupBy {
+			return ""
+		}
+		return fmt.Sprintf("\n  - _Issues per %s_", c.GroupBy)
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "\n  - _Issues per %s, stacked by %s:_", c.GroupBy, c.StackBy)
+	for i, stack := range c.stackOrder() {
+		fmt.Fprintf(&sb, " <span style=\"color:%s\">■</span> %s", stackColors[i%len(stackColors)], stack)
+	}
+	return sb.String()
+}
+
+// barDataURI draws the count of each group after the total of distinct issues, split by the
+// stacked values when the count is stacked.
+func (c *BugStatusCount) barDataURI() (string, error) {
+	keys, values := getKeyValueFromMap(c.Groups)
+	if c.StackBy == "" {
+		return generateBarDataURI(bugStatusWidth, bugStatusHeight, keys, values, c.Total)
+	}
+
+	labels := []string{strconv.Quote(fmt.Sprintf("TOTAL (%d)", c.Total))}
+	for i, key := range keys {
+		labels = append(labels, strconv.Quote(fmt.Sprintf("%s (%d)", key, values[i])))
+	}
+	var series []string
+	for i, stack := range c.stackOrder() {
+		data := []string{stackValue(c.StackTotals[stack])}
+		for _, key := range keys {
+			data = append(data, stackValue(c.Stacks[key][stack]))
+		}
+		series = append(series, fmt.Sprintf(simpleOptsStackedSeries, strconv.Quote(stack),
+			strings.Join(data, ", "), stackColors[i%len(stackColors)]))
+	}
+	options := fmt.Sprintf(simpleOptsStackedBar, strings.Join(labels, ", "), strings.Join(series, ",\n"))
+	return renderChart(bugStatusWidth, bugStatusHeight, options)
+}
+
+// issueLists lists the issues of each group, largest groups first, by priority and then from
+// the oldest. The groups with more than maxInlineIssues issues are collapsed.
+func (c *BugStatusCount) issueLists(jiraURL string, now time.Time) string {
+	groups := make([]string, 0, len(c.Groups))
+	for group := range c.Groups {
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if c.Groups[groups[i]] != c.Groups[groups[j]] {
+			return c.Groups[groups[i]] > c.Groups[groups[j]]
+		}
+		return groups[i] < groups[j]
+	})
+	issues := append([]bugStatusIssue(nil), c.issues...)
+	sort.SliceStable(issues, func(i, j int) bool {
+		if ri, rj := priorityRank(issues[i].Priority), priorityRank(issues[j].Priority); ri != rj {
+			return ri < rj
+		}
+		return issues[i].Created.Before(issues[j].Created)
+	})
+
+	var sb strings.Builder
+	for _, group := range groups {
+		collapsed := c.Groups[group] > maxInlineIssues
+		if collapsed {
+			fmt.Fprintf(&sb, "\n<details><summary>%s (%d)</summary>\n\n", html.EscapeString(group), c.Groups[group])
+		} else {
+			fmt.Fprintf(&sb, "\n**%s** (%d)\n\n", markdownCell(group), c.Groups[group])
+		}
+		sb.WriteString("| Key | Summary | Priority | Assignee | Age |\n| --- | --- | --- | --- | --- |\n")
+		for _, issue := range issues {
+			if !slices.Contains(issue.Groups, group) {
+				continue
+			}
+			assignee := issue.Assignee
+			if assignee == "" {
+				assignee = "Unassigned"
+			}
+			age := ""
+			if !issue.Created.IsZero() {
+				age = fmt.Sprintf("%d days", int(now.Sub(issue.Created).Hours()/hoursPerDay))
+			}
+			fmt.Fprintf(&sb, "| [%s](%s) | %s | %s | %s | %s |\n", issue.Key, issueURL(jiraURL, issue.Key),
+				markdownCell(issue.Summary), markdownCell(issue.Priority), markdownCell(assignee), age)
+		}
+		if collapsed {
+			sb.WriteString("\n</details>\n")
+		}
+	}
+	return sb.String()
+}
+
+func priorityRank(priority string) int {
+	if i := slices.Index(priorityOrder, priority); i >= 0 {
+		return i
+	}
+	return len(priorityOrder)
+}
+
+// markdownCell escapes the text of a markdown table cell.
+func markdownCell(text string) string {
+	return strings.NewReplacer("|", "\\|", "<", "&lt;", "\n", " ", "\r", "").Replace(text)
+}
+
+// stackValue leaves the empty parts of a stacked bar out of the chart.
+func stackValue(value int) string {
+	if value == 0 {
+		return "null"
+	}
+	return strconv.Itoa(value)
+}
+
+const simpleOptsStackedBar = `{
+  "backgroundColor": "white",
+  "grid": { "left": 260, "top": 10, "bottom": 10, "right": 50 },
+  "xAxis": { "type": "value", "axisLine": { "show": false }, "axisTick": { "show": false },
+    "splitLine": { "show": false }, "axisLabel": { "show": false } },
+  "yAxis": { "type": "category", "data": [%s], "axisLabel": { "align": "left", "margin": 250, "fontSize": 10 },
+    "axisLine": { "show": false }, "axisTick": { "show": false } },
+  "series": [
+%s
+  ]
+}`
+
+const simpleOptsStackedSeries = `    { "name": %s, "type": "bar", "stack": "total", "barWidth": "70%%", "data": [%s],
+      "itemStyle": { "color": "%s" },
+      "label": { "show": true, "formatter": "{c}", "color": "#000000", "fontSize": 9 } }`
+
+// BugStatusThreshold is the most issues, and the age in days of the oldest one, of a RAG
+// color. A limit left out is not checked.
+type BugStatusThreshold struct {
+	Count *int `yaml:"count"`
+	Age   *int `yaml:"age"`
+}
+
+// BugStatusThresholds give the RAG status of a bugstatus.yml filter: green within the green
+// limits, yellow within the yellow ones and red above them.
+type BugStatusThresholds struct {
+	Green  *BugStatusThreshold `yaml:"green"`
+	Yellow *BugStatusThreshold `yaml:"yellow"`
+}
+
+func (t *BugStatusThreshold) allows(count, oldestDays int) bool {
+	return t != nil && (t.Count == nil || count <= *t.Count) && (t.Age == nil || oldestDays <= *t.Age)
+}
+
+// rag returns the color of the count of issues and the age of the oldest one, empty without
+// thresholds.
+func (t *BugStatusThresholds) rag(count, oldestDays int) string {
+	switch {
+	case t == nil:
+		return ""
+	case t.Green.allows(count, oldestDays):
+		return colorGreen
+	case t.Yellow.allows(count, oldestDays):
+		return colorYellow
+	default:
+		return colorRed
+	}
+}
+
+// oldestDays returns the age of the oldest issue.
+func (c *BugStatusCount) oldestDays(now time.Time) int {
+	oldest := 0
+	for _, issue := range c.issues {
+		if !issue.Created.IsZero() {
+			oldest = max(oldest, int(now.Sub(issue.Created).Hours()/hoursPerDay))
+		}
+	}
+	return oldest
+}
+
+// RAGRank orders the RAG statuses: 1 for green, 2 for yellow, 3 for red and 0 without status.
+func RAGRank(rag string) int {
+	switch {
+	case strings.EqualFold(rag, colorGreen):
+		return 1
+	case strings.EqualFold(rag, colorYellow):
+		return 2 //nolint:mnd
+	case strings.EqualFold(rag, colorRed):
+		return 3 //nolint:mnd
+	default:
+		return 0
+	}
+}
+
+// WorstRAG returns the worst RAG status of the counts, empty when no filter has thresholds.
+func WorstRAG(counts []BugStatusCount) string {
+	worst := ""
+	for i := range counts {
+		if RAGRank(counts[i].RAG) > RAGRank(worst) {
+			worst = counts[i].RAG
+		}
+	}
+	return worst
+}
+
+// RAGReached tells whether a RAG status is at least as bad as level, yellow or red.
+func RAGReached(rag, level string) bool {
+	return RAGRank(level) > 0 && RAGRank(rag) >= RAGRank(level)
+}
+
+// ragBadge is the colored label of a RAG status, shown after the filter name.
+func ragBadge(rag string) string {
+	switch rag {
+	case colorRed:
+		return " <span style=\"background-color:red; color:white\">RED</span>"
+	case colorYellow:
+		return " <span style=\"background-color:yellow; color:black\">YELLOW</span>"
+	case colorGreen:
+		return " <span style=\"background-color:green; color:white\">GREEN</span>"
+	default:
+		return ""
+	}
+}
+
+// writeBugStatusSummary writes a table of the issue count, oldest issue and RAG status of
+// every filter.
+func writeBugStatusSummary(w io.Writer, counts []BugStatusCount) {
+	fmt.Fprintln(w, "| Filter | Issues | Oldest | Status |\n| --- | ---: | ---: | --- |")
+	for i := range counts {
+		count := &counts[i]
+		status := strings.TrimSpace(ragBadge(count.RAG))
+		if status == "" {
+			status = "-"
+		}
+		fmt.Fprintf(w, "| [%s](%s) | %d | %d days | %s |\n", markdownCell(count.Name), count.URL, count.Total,
+			count.OldestDays, status)
+	}
+}
@@ -0,0 +1,198 @@
+package reports
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	gaMilestone = "GA"
+	// FeatureFreezeMilestone is the milestone after which the scope of a release is frozen.
+	FeatureFreezeMilestone = "Feature freeze"
+)
+
+//go:embed calendar/releases.yml
+var releaseCalendarYAML []byte
+
+// workflowStatuses are the statuses of the release issues, from the earliest to the latest.
+var workflowStatuses = []string{"New", "To Do", "Planning", "In Progress", "Dev Complete", "Release Pending", "Closed"}
+
+// Milestone is a date of a release cycle.
+type Milestone struct {
+	Name string    `yaml:"name"`
+	Date time.Time `yaml:"date"`
+	// Status is the workflow status the release issues should have reached by the milestone,
+	// not checked when empty.
+	Status string `yaml:"status"`
+}
+
+// Release is a release of the calendar with its milestones in date order.
+type Release struct {
+	Release    string      `yaml:"release"`
+	Milestones []Milestone `yaml:"milestones"`
+}
+
+// ReleaseCalendar is the list of releases of the calendar file.
+type ReleaseCalendar []Release
+
+// LoadReleaseCalendar reads the release calendar file, or the embedded calendar when file is
+// empty.
+func LoadReleaseCalendar(file string) (ReleaseCalendar, error) {
+	calendarYAML := releaseCalendarYAML
+	if file != "" {
+		var err error
+		calendarYAML, err = os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var calendar ReleaseCalendar
+	if err := yaml.Unmarshal(calendarYAML, &calendar); err != nil {
+		return nil, err
+	}
+	for i := range calendar {
+		release := &calendar[i]
+		for _, milestone := range release.Milestones {
+			if milestone.Date.IsZero() {
+				return nil, fmt.Errorf("release %s: milestone %q has no date", release.Release, milestone.Name)
+			}
+			if milestone.Status != "" && !slices.Contains(workflowStatuses, milestone.Status) {
+				return nil, fmt.Errorf("release %s: milestone %q status %q is not one of %s", release.Release,
+					milestone.Name, milestone.Status, strings.Join(workflowStatuses, ", "))
+			}
+		}
+		sort.SliceStable(release.Milestones, func(a, b int) bool {
+			return release.Milestones[a].Date.Before(release.Milestones[b].Date)
+		})
+	}
+	return calendar, nil
+}
+
+// Find returns the release of the calendar, nil when it is not in the calendar.
+func (c ReleaseCalendar) Find(release string) *Release {
+	for i := range c {
+		if c[i].Release == release {
+			return &c[i]
+		}
+	}
+	return nil
+}
+
+// Current returns the release whose GA is the first one not passed, nil when all are.
+func (c ReleaseCalendar) Current(now time.Time) *Release {
+	today := dateOnly(now)
+	var current *Release
+	for i := range c {
+		ga, ok := c[i].GA()
+		if !ok || ga.Before(today) {
+			continue
+		}
+		if current == nil {
+			current = &c[i]
+		} else if currentGA, _ := current.GA(); ga.Before(currentGA) {
+			current = &c[i]
+		}
+	}
+	return current
+}
+
+// GA returns the date of the GA milestone of the release.
+func (r *Release) GA() (time.Time, bool) {
+	return r.Milestone(gaMilestone)
+}
+
+// Milestone returns the date of the milestone of the release, the name compared regardless of case.
+func (r *Release) Milestone(name string) (time.Time, bool) {
+	for _, milestone := range r.Milestones {
+		if strings.EqualFold(milestone.Name, name) {
+			return milestone.Date, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// upcoming returns the first milestone not passed, nil after the last one.
+func (r *Release) upcoming(now time.Time) *Milestone {
+	today := dateOnly(now)
+	for i := range r.Milestones {
+		if !r.Milestones[i].Date.Before(today) {
+			return &r.Milestones[i]
+		}
+	}
+	return nil
+}
+
+// statusRank orders the statuses of workflowStatuses. The other statuses rank as New, unless
+// they are done.
+func statusRank(issue *jira.Issue) int {
+	if issue.Fields.Status == nil {
+		return 0
+	}
+	if i := slices.Index(workflowStatuses, issue.Fields.Status.Name); i >= 0 {
+		return i
+	}
+	if issue.Fields.Status.StatusCategory.Key == jira.StatusCategoryComplete {
+		return len(workflowStatuses) - 1
+	}
+	return 0
+}
+
+// behind returns the upcoming milestone when the status of the issue is earlier than the one it
+// expects, nil otherwise.
+func (r *Release) behind(issue *jira.Issue, now time.Time) *Milestone {
+	if r == nil {
+		return nil
+	}
+	milestone := r.upcoming(now)
+	if milestone == nil || milestone.Status == "" {
+		return nil
+	}
+	if statusRank(issue) < slices.Index(workflowStatuses, milestone.Status) {
+		return milestone
+	}
+	return nil
+}
+
+// behindLabel flags an issue behind the upcoming milestone, after its link.
+func behindLabel(milestone *Milestone, now time.Time) string {
+	return fmt.Sprintf(" _(behind %s in %s: %s expected)_", milestone.Name, daysUntil(milestone.Date, now),
+		milestone.Status)
+}
+
+func daysUntil(date, now time.Time) string {
+	days := int(date.Sub(dateOnly(now)).Hours() / hoursPerDay)
+	if days == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", days)
+}
+
+// milestonesMarkdown is the countdown to the milestones of the release, with the number of
+// issues behind the upcoming one.
+func (r *Release) milestonesMarkdown(behind int, now time.Time) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "<span style=\"background-color:black; color:white\">RELEASE %s</span>\n\n", r.Release)
+	upcoming := r.upcoming(now)
+	for i := range r.Milestones {
+		milestone := &r.Milestones[i]
+		date := milestone.Date.Format(time.DateOnly)
+		switch {
+		case milestone.Date.Before(dateOnly(now)):
+			fmt.Fprintf(&sb, "- %s: %s, passed\n", milestone.Name, date)
+		case milestone == upcoming && milestone.Status != "":
+			fmt.Fprintf(&sb, "- **%s: %s, in %s**, %d issues behind (%s expected)\n", milestone.Name, date,
+				daysUntil(milestone.Date, now), behind, milestone.Status)
+		default:
+			fmt.Fprintf(&sb, "- %s: %s, in %s\n", milestone.Name, date, daysUntil(milestone.Date, now))
+		}
+	}
+	return sb.String()
+}
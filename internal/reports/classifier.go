@@ -0,0 +1,159 @@
+package reports
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+	"github.com/edcdavid/jira-helper/internal/ollamahelper"
+	"github.com/edcdavid/jira-helper/internal/prompts"
+)
+
+const (
+	colorGreen  = "Green"
+	colorYellow = "Yellow"
+	colorRed    = "Red"
+
+	hoursPerDay = 24
+)
+
+var (
+	redKeywords    = regexp.MustCompile(`(?i)\b(blocked|blocker|slip|slipped|slipping|at risk|will not make|won't make|descoped?)\b`)
+	yellowKeywords = regexp.MustCompile(`(?i)\b(risk|risky|delay|delayed|waiting|concern|pending|behind)\b`)
+	aiColorRe      = regexp.MustCompile(`(?i)color:\s*\**(green|yellow|red)\**`)
+	aiReasonRe     = regexp.MustCompile(`(?is)reason:\s*(.+)`)
+)
+
+// colorSuggestion is a health color guessed for an issue whose owner did not set one.
+type colorSuggestion struct {
+	Color   string
+	Reasons []string
+}
+
+// colorSignal is one heuristic finding with the color it points to.
+type colorSignal struct {
+	Color  string
+	Reason string
+}
+
+// suggestColorPromptData is the data made available to the suggest color prompt template.
+type suggestColorPromptData struct {
+	Key           string
+	Title         string
+	State         string
+	StatusSummary string
+	Description   string
+	Signals       []colorSignal
+}
+
+func colorRank(color string) int {
+	switch color {
+	case colorRed:
+		return 2 //nolint:mnd
+	case colorYellow:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// heuristicColorSignals collects the risk signals of an issue: due date passed, no update for
+// staleDays, open blocking links and worrying keywords in its status summary, description or
+// latest comment.
+func heuristicColorSignals(issue *jira.Issue, statusSummary string, staleDays int, now time.Time) []colorSignal {
+	var signals []colorSignal
+	done := issue.Fields.Status != nil && issue.Fields.Status.StatusCategory.Key == jira.StatusCategoryComplete
+
+	dueDate := time.Time(issue.Fields.Duedate)
+	if !done && !dueDate.IsZero() && dueDate.Before(now) {
+		signals = append(signals, colorSignal{colorRed, "due date " + dueDate.Format(time.DateOnly) + " has passed"})
+	}
+
+	updated := time.Time(issue.Fields.Updated)
+	if !done && staleDays > 0 && !updated.IsZero() && now.Sub(updated) > time.Duration(staleDays)*hoursPerDay*time.Hour {
+		signals = append(signals, colorSignal{colorYellow,
+			fmt.Sprintf("no update in %d days", int(now.Sub(updated).Hours()/hoursPerDay))})
+	}
+
+	for _, link := range issue.Fields.IssueLinks {
+		if link.Type.Name != "Blocks" || link.InwardIssue == nil {
+			continue
+		}
+		blocker := link.InwardIssue
+		if blocker.Fields != nil && blocker.Fields.Status != nil &&
+			blocker.Fields.Status.StatusCategory.Key == jira.StatusCategoryComplete {
+			continue
+		}
+		signals = append(signals, colorSignal{colorYellow, "is blocked by open issue " + blocker.Key})
+	}
+
+	text := statusSummary + "\n" + issue.Fields.Description
+	if issue.Fields.Comments != nil && len(issue.Fields.Comments.Comments) > 0 {
+		text += "\n" + issue.Fields.Comments.Comments[len(issue.Fields.Comments.Comments)-1].Body
+	}
+	if match := redKeywords.FindString(text); match != "" {
+		signals = append(signals, colorSignal{colorRed, fmt.Sprintf("mentions %q", strings.ToLower(match))})
+	} else if match := yellowKeywords.FindString(text); match != "" {
+		signals = append(signals, colorSignal{colorYellow, fmt.Sprintf("mentions %q", strings.ToLower(match))})
+	}
+	return signals
+}
+
+// suggestColor combines the heuristic signals into a suggestion: the worst color among the
+// signals, or Green when none was found. When ollamaModel is set, the model gets the issue and
+// the signals and its answer replaces the heuristic one.
+func suggestColor(issue *jira.Issue, statusSummary string, staleDays int, ollamaModel, promptOverride string) (colorSuggestion, error) {
+	signals := heuristicColorSignals(issue, statusSummary, staleDays, time.Now())
+
+	suggestion := colorSuggestion{Color: colorGreen}
+	for _, signal := range signals {
+		if colorRank(signal.Color) > colorRank(suggestion.Color) {
+			suggestion.Color = signal.Color
+		}
+		suggestion.Reasons = append(suggestion.Reasons, signal.Reason)
+	}
+	if len(signals) == 0 {
+		suggestion.Reasons = []string{"no risk signal found"}
+	}
+
+	if ollamaModel == "" {
+		return suggestion, nil
+	}
+
+	prompt, err := prompts.Render(prompts.SuggestColor, promptOverride, suggestColorPromptData{
+		Key:           issue.Key,
+		Title:         issue.Fields.Summary,
+		State:         issue.Fields.Status.Name,
+		StatusSummary: statusSummary,
+		Description:   issue.Fields.Description,
+		Signals:       signals,
+	})
+	if err != nil {
+		return suggestion, fmt.Errorf("cannot render suggest color prompt: %w", err)
+	}
+	answer, err := ollamahelper.Chat(context.Background(), ollamaModel, "", prompt)
+	if err != nil {
+		return suggestion, fmt.Errorf("suggest color chat request failed: %w", err)
+	}
+
+	colorMatch := aiColorRe.FindStringSubmatch(answer)
+	reasonMatch := aiReasonRe.FindStringSubmatch(answer)
+	if colorMatch == nil || reasonMatch == nil {
+		log.Printf("Ignoring unexpected suggest color answer for %s: %s", issue.Key, answer)
+		return suggestion, nil
+	}
+	return colorSuggestion{
+		Color:   strings.ToUpper(colorMatch[1][:1]) + strings.ToLower(colorMatch[1][1:]),
+		Reasons: []string{strings.TrimSpace(strings.SplitN(reasonMatch[1], "\n", 2)[0]) + " (AI)"}, //nolint:mnd
+	}, nil
+}
+
+// markdown renders the suggestion as a report bullet. It is worded so that it cannot be taken
+// for a color set by the issue owner.
+func (s colorSuggestion) markdown() string {
+	return fmt.Sprintf("    - _Suggested color (not set by owner): **%s** - %s_\n", s.Color, strings.Join(s.Reasons, "; "))
+}
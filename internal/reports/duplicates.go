@@ -0,0 +1,171 @@
+package reports
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+	"github.com/edcdavid/jira-helper/internal/embeddings"
+	"github.com/edcdavid/jira-helper/internal/jirahelper"
+)
+
+// DuplicatesOptions configures GetDuplicatesReport.
+type DuplicatesOptions struct {
+	JiraURL             string
+	PersonalAccessToken string
+	// FilterQuery replaces the bugstatus.yml queries when set.
+	FilterQuery       string
+	ReleaseCutoffDate string
+	FromDate          string
+	EmbeddingModel    string
+	EmbeddingsFile    string
+	// Threshold is the cosine similarity above which two issues are likely duplicates.
+	Threshold float64
+}
+
+type duplicatePair struct {
+	a, b       string
+	similarity float64
+}
+
+// GetDuplicatesReport prints the clusters of likely duplicate issues found in the bugstatus.yml
+// queries, comparing the embeddings of their summary and description.
+func GetDuplicatesReport(opts DuplicatesOptions) {
+	initLog()
+	client, err := jirahelper.NewClient(opts.JiraURL, opts.PersonalAccessToken)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	queries := []string{opts.FilterQuery}
+	if opts.FilterQuery == "" {
+		filters, err := loadFilters(bugStatusFiltersYAML)
+		if err != nil {
+			log.Fatalf("Cannot load embedded filters, err:%v", err)
+		}
+		queries = nil
+		for _, filter := range filters {
+			queries = append(queries, patchFilter(filter, opts.ReleaseCutoffDate, opts.FromDate))
+		}
+	}
+
+	issuesByKey := map[string]jira.Issue{}
+	for _, query := range queries {
+		issues, err := jirahelper.FetchAllIssues(context.TODO(), client, query, maxIssuesRetrieved)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, issue := range issues {
+			issuesByKey[issue.Key] = issue
+		}
+	}
+
+	texts := map[string]string{}
+	for key, issue := range issuesByKey {
+		texts[key] = issue.Fields.Summary + "\n\n" + issue.Fields.Description
+	}
+
+	store, err := embeddings.Load(opts.EmbeddingsFile, opts.EmbeddingModel)
+	if err != nil {
+		log.Fatalf("Cannot load embeddings file %s, err:%v", opts.EmbeddingsFile, err)
+	}
+	computed, err := store.Update(context.Background(), texts)
+	if err != nil {
+		log.Fatalf("Cannot compute embeddings with model %s, err:%v", opts.EmbeddingModel, err)
+	}
+	pruned := store.Prune(texts)
+	if err := store.Save(opts.EmbeddingsFile); err != nil {
+		log.Fatalf("Cannot save embeddings file %s, err:%v", opts.EmbeddingsFile, err)
+	}
+	log.Printf("Computed %d embeddings, reused %d, dropped %d", computed, len(texts)-computed, pruned)
+
+	clusters := clusterDuplicates(store, texts, opts.Threshold)
+	fmt.Printf("%d clusters of likely duplicates among %d issues (similarity >= %.2f)\n",
+		len(clusters), len(texts), opts.Threshold)
+	for i, cluster := range clusters {
+		fmt.Printf("\n- Cluster %d (similarity up to %.2f)\n", i+1, cluster.maxSimilarity)
+		for _, key := range cluster.keys {
+			issue := issuesByKey[key]
+			fmt.Printf("  - [%s: %s](%s) %s\n", key, issue.Fields.Summary, issueURL(opts.JiraURL, key),
+				componentNames(&issue))
+		}
+	}
+}
+
+type duplicateCluster struct {
+	keys          []string
+	maxSimilarity float64
+}
+
+// clusterDuplicates links every pair of issues at least threshold similar, and returns the
+// connected groups, largest first.
+func clusterDuplicates(store *embeddings.Store, texts map[string]string, threshold float64) []duplicateCluster {
+	keys := make([]string, 0, len(texts))
+	for key := range texts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var pairs []duplicatePair
+	for i := range keys {
+		for j := i + 1; j < len(keys); j++ {
+			similarity := embeddings.Cosine(store.Issues[keys[i]].Vector, store.Issues[keys[j]].Vector)
+			if similarity >= threshold {
+				pairs = append(pairs, duplicatePair{keys[i], keys[j], similarity})
+			}
+		}
+	}
+
+	parent := map[string]string{}
+	var find func(string) string
+	find = func(key string) string {
+		if parent[key] == "" || parent[key] == key {
+			return key
+		}
+		parent[key] = find(parent[key])
+		return parent[key]
+	}
+	for _, pair := range pairs {
+		parent[find(pair.a)] = find(pair.b)
+	}
+
+	byRoot := map[string]*duplicateCluster{}
+	for _, pair := range pairs {
+		root := find(pair.a)
+		if byRoot[root] == nil {
+			byRoot[root] = &duplicateCluster{}
+		}
+		byRoot[root].maxSimilarity = max(byRoot[root].maxSimilarity, pair.similarity)
+	}
+	for _, key := range keys {
+		if cluster, ok := byRoot[find(key)]; ok {
+			cluster.keys = append(cluster.keys, key)
+		}
+	}
+
+	clusters := make([]duplicateCluster, 0, len(byRoot))
+	for _, cluster := range byRoot {
+		clusters = append(clusters, *cluster)
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		if len(clusters[i].keys) != len(clusters[j].keys) {
+			return len(clusters[i].keys) > len(clusters[j].keys)
+		}
+		return clusters[i].keys[0] < clusters[j].keys[0]
+	})
+	return clusters
+}
+
+func componentNames(issue *jira.Issue) string {
+	names := []string{}
+	for _, component := range issue.Fields.Components {
+		names = append(names, component.Name)
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	return "(" + strings.Join(names, ", ") + ")"
+}
@@ -0,0 +1,67 @@
+package reports
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/edcdavid/jira-helper/internal/email"
+)
+
+// EmailOptions configures GetEmailReport. The message is written to EMLFile, sent through
+// SMTP.Addr, or both.
+type EmailOptions struct {
+	Message email.Message
+	EMLFile string
+	SMTP    email.SMTPOptions
+}
+
+// GetEmailReport renders the markdown report as an email with inline charts.
+func GetEmailReport(opts ReportOptions, emailOpts EmailOptions) {
+	initLog()
+	if err := writeEmailReport(context.TODO(), &opts, &emailOpts); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func writeEmailReport(ctx context.Context, opts *ReportOptions, emailOpts *EmailOptions) error {
+	if emailOpts.EMLFile == "" && emailOpts.SMTP.Addr == "" {
+		return errors.New("an .eml file or an SMTP server is required for the email output")
+	}
+	if emailOpts.SMTP.Addr != "" && (emailOpts.Message.From == "" || len(emailOpts.Message.To) == 0) {
+		return errors.New("a sender and at least one recipient are required to send the email")
+	}
+	if emailOpts.Message.Subject == "" {
+		emailOpts.Message.Subject = fmt.Sprintf("Release %s status - %s", opts.Release, time.Now().Format(time.DateOnly))
+		if opts.FilterQuery != "" {
+			emailOpts.Message.Subject = "Status report - " + time.Now().Format(time.DateOnly)
+		}
+	}
+
+	var markdown bytes.Buffer
+	if err := WriteMarkdownReport(ctx, &markdown, opts); err != nil {
+		return err
+	}
+	data, err := email.Build(markdown.String(), &emailOpts.Message)
+	if err != nil {
+		return fmt.Errorf("cannot build the email, err:%w", err)
+	}
+
+	if emailOpts.EMLFile != "" {
+		if err := os.WriteFile(emailOpts.EMLFile, data, 0o644); err != nil { //nolint:gosec,mnd
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Email written to %s\n", emailOpts.EMLFile)
+	}
+	if emailOpts.SMTP.Addr != "" {
+		if err := email.Send(&emailOpts.SMTP, &emailOpts.Message, data); err != nil {
+			return fmt.Errorf("cannot send the email through %s, err:%w", emailOpts.SMTP.Addr, err)
+		}
+		fmt.Fprintf(os.Stderr, "Email sent to %d recipient(s)\n", len(emailOpts.Message.To))
+	}
+	return nil
+}
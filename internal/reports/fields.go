@@ -0,0 +1,148 @@
+package reports
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+	"github.com/edcdavid/jira-helper/internal/jirahelper"
+)
+
+const (
+	colorField         = jirahelper.ColorField
+	statusSummaryField = jirahelper.StatusSummaryField
+
+	jiraTimeLayout = jirahelper.TimeLayout
+)
+
+// fieldValues returns the values of an issue field as strings. The field is one of the
+// aliases below, a custom field id, or a field name resolved with fieldIDs.
+func fieldValues(issue *jira.Issue, field string, fieldIDs map[string]string) []string { //nolint:funlen,gocyclo
+	fields := issue.Fields
+	switch strings.ToLower(field) {
+	case "key":
+		return []string{issue.Key}
+	case "summary":
+		return nonEmpty(fields.Summary)
+	case "color":
+		return nonEmpty(getCustomField(colorField, issue))
+	case "statussummary":
+		return nonEmpty(strings.TrimSpace(getCustomField(statusSummaryField, issue)))
+	case "status":
+		if fields.Status != nil {
+			return nonEmpty(fields.Status.Name)
+		}
+	case "statuscategory":
+		if fields.Status != nil {
+			return nonEmpty(fields.Status.StatusCategory.Name)
+		}
+	case "issuetype":
+		return nonEmpty(fields.Type.Name)
+	case "project":
+		return nonEmpty(fields.Project.Key)
+	case "priority":
+		if fields.Priority != nil {
+			return nonEmpty(fields.Priority.Name)
+		}
+	case "assignee":
+		if fields.Assignee != nil {
+			return nonEmpty(fields.Assignee.DisplayName)
+		}
+	case "reporter":
+		if fields.Reporter != nil {
+			return nonEmpty(fields.Reporter.DisplayName)
+		}
+	case "resolution":
+		if fields.Resolution != nil {
+			return nonEmpty(fields.Resolution.Name)
+		}
+	case "component", "components":
+		var values []string
+		for _, component := range fields.Components {
+			values = append(values, component.Name)
+		}
+		return values
+	case "label", "labels":
+		return fields.Labels
+	case "fixversion", "fixversions":
+		var values []string
+		for _, version := range fields.FixVersions {
+			values = append(values, version.Name)
+		}
+		return values
+	case "duedate":
+		return nonZeroTime(time.Time(fields.Duedate), time.DateOnly)
+	case "created":
+		return nonZeroTime(time.Time(fields.Created), time.RFC3339)
+	case "updated":
+		return nonZeroTime(time.Time(fields.Updated), time.RFC3339)
+	case "resolved", "resolutiondate":
+		return nonZeroTime(time.Time(fields.Resolutiondate), time.RFC3339)
+	default:
+		id := field
+		if fieldID, ok := fieldIDs[field]; ok {
+			id = fieldID
+		} else {
+			// Names are matched regardless of case, as severity for the Severity field.
+			for name, fieldID := range fieldIDs {
+				if strings.EqualFold(name, field) {
+					id = fieldID
+					break
+				}
+			}
+		}
+		return customFieldStrings(fields.Unknowns[id])
+	}
+	return nil
+}
+
+// customFieldStrings flattens the JSON value of a custom field: options, users and versions
+// give their value, name or display name.
+func customFieldStrings(value any) []string {
+	switch typed := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return nonEmpty(strings.TrimSpace(typed))
+	case float64:
+		return []string{fmt.Sprint(typed)}
+	case []any:
+		var values []string
+		for _, item := range typed {
+			values = append(values, customFieldStrings(item)...)
+		}
+		return values
+	case map[string]any:
+		for _, key := range []string{"value", "displayName", "name", "key"} {
+			if str, ok := typed[key].(string); ok {
+				return nonEmpty(str)
+			}
+		}
+	}
+	return nil
+}
+
+// parseJiraDate parses the date and date time formats used by the Jira REST API.
+func parseJiraDate(value string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339, jiraTimeLayout, time.DateOnly} {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func nonEmpty(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return []string{value}
+}
+
+func nonZeroTime(value time.Time, layout string) []string {
+	if value.IsZero() {
+		return nil
+	}
+	return []string{value.Format(layout)}
+}
@@ -0,0 +1,258 @@
+package reports
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+	"github.com/edcdavid/jira-helper/internal/jirahelper"
+)
+
+const (
+	// maxForecastWeeks bounds a simulation whose sampled weeks resolve almost nothing.
+	maxForecastWeeks = 520
+
+	onTimeGreen  = 85
+	onTimeYellow = 50
+)
+
+// forecastPercentiles are the completion dates reported, as percentages of the simulations done.
+var forecastPercentiles = []int{50, 85, 95}
+
+// ForecastOptions configures GetForecastReport.
+type ForecastOptions struct {
+	JiraURL             string
+	PersonalAccessToken string
+	FilterQuery         string
+	Release             string
+	CustomerFacing      string
+	// GA is the date the completion is forecast against.
+	GA string
+	// Weeks is the number of past weeks the throughput is sampled from.
+	Weeks int
+	// Simulations is the number of simulated completions.
+	Simulations int
+}
+
+// forecast is the outcome of the Monte Carlo simulation of the remaining work.
+type forecast struct {
+	Issues    int
+	Remaining int
+	// Epics is the number of issues of the query whose work is counted on their children.
+	Epics int
+	// Childless is the number of issues of the query without children, counted themselves.
+	Childless int
+	// Throughput is the number of issues resolved each past week, the oldest week first.
+	Throughput []int
+	// Weeks are the simulated numbers of weeks to complete, sorted.
+	Weeks []int
+	Today time.Time
+	GA    time.Time
+}
+
+// GetForecastReport simulates the completion of the remaining work of the report query from the
+// weekly throughput of the past weeks, and prints the completion dates and the probability to
+// complete by GA.
+func GetForecastReport(opts ForecastOptions) {
+	initLog()
+	ga, err := time.Parse(time.DateOnly, opts.GA)
+	if err != nil {
+		log.Fatalf("Invalid GA date %q, err:%v", opts.GA, err)
+	}
+	if opts.Weeks <= 0 || opts.Simulations <= 0 {
+		log.Fatal("the number of weeks and of simulations must be positive")
+	}
+	filterQuery, err := ReportQuery(&ReportOptions{
+		FilterQuery: opts.FilterQuery, Release: opts.Release, CustomerFacing: opts.CustomerFacing,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	client, err := jirahelper.NewClient(opts.JiraURL, opts.PersonalAccessToken)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	issues, err := jirahelper.FetchAllIssues(context.TODO(), client, filterQuery, maxIssuesRetrieved)
+	if err != nil {
+		log.Fatal(err)
+	}
+	work, epics, childless, err := fetchForecastWork(context.TODO(), client, issues)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	now := time.Now()
+	f := &forecast{Issues: len(issues), Epics: epics, Childless: childless, Today: dateOnly(now), GA: ga}
+	f.Throughput = weeklyThroughput(work, now, opts.Weeks)
+	for i := range work {
+		if work[i].Fields.Status == nil || work[i].Fields.Status.StatusCategory.Key != jira.StatusCategoryComplete {
+			f.Remaining++
+		}
+	}
+	rng := rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())) //nolint:gosec
+	f.Weeks, err = simulateCompletion(rng, f.Remaining, f.Throughput, opts.Simulations)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := f.write(os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// fetchForecastWork returns the children of the epics and the issues without children, all
+// with their changelog, along with the number of issues with children and without. An issue
+// that is the child of another one of the query is only counted once, as a child.
+func fetchForecastWork(ctx context.Context, client *jira.Client, issues []jira.Issue) (work []jira.Issue, epics, childless int, err error) {
+	fieldIDs, err := jirahelper.FieldIDsByName(ctx, client)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	epicLinkID := fieldIDs[epicLinkFieldName]
+	children, err := fetchInBatches(ctx, client, issues, func(keys []string) string {
+		return epicChildrenQuery(keys, epicLinkID)
+	})
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	parents := map[string]bool{}
+	isChild := map[string]bool{}
+	for i := range children {
+		parents[childEpicKey(&children[i], epicLinkID)] = true
+		isChild[children[i].Key] = true
+	}
+	var rest []jira.Issue
+	for i := range issues {
+		switch {
+		case parents[issues[i].Key]:
+			epics++
+		case !isChild[issues[i].Key]:
+			rest = append(rest, issues[i])
+		}
+	}
+	own, err := fetchInBatches(ctx, client, rest, func(keys []string) string {
+		return fmt.Sprintf("key in (%s)", strings.Join(keys, ", "))
+	})
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return append(children, own...), epics, len(own), nil
+}
+
+// fetchInBatches fetches with their changelog the issues of the queries of batches of keys.
+func fetchInBatches(ctx context.Context, client *jira.Client, issues []jira.Issue, query func(keys []string) string) ([]jira.Issue, error) {
+	var result []jira.Issue
+	for start := 0; start < len(issues); start += rollupBatchSize {
+		batch := issues[start:min(start+rollupBatchSize, len(issues))]
+		keys := make([]string, len(batch))
+		for i := range batch {
+			keys[i] = batch[i].Key
+		}
+		fetched, err := jirahelper.FetchAllIssuesExpanded(ctx, client, query(keys), maxIssuesRetrieved, "changelog")
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, fetched...)
+	}
+	return result, nil
+}
+
+// weeklyThroughput counts the issues resolved in each of the past weeks ending today, from the
+// resolutions of their changelog.
+func weeklyThroughput(issues []jira.Issue, now time.Time, weeks int) []int {
+	throughput := make([]int, weeks)
+	start := now.AddDate(0, 0, -weeks*daysPerWeek)
+	for i := range issues {
+		for _, change := range resolutionChanges(&issues[i]) {
+			if !change.resolved || change.at.Before(start) || change.at.After(now) {
+				continue
+			}
+			week := min(int(change.at.Sub(start).Hours()/hoursPerDay/daysPerWeek), weeks-1)
+			throughput[week]++
+		}
+	}
+	return throughput
+}
+
+// simulateCompletion draws the throughput of each future week from the past weeks with rng until
+// the remaining issues are resolved, and returns the sorted number of weeks of each simulation.
+func simulateCompletion(rng *rand.Rand, remaining int, throughput []int, simulations int) ([]int, error) {
+	weeks := make([]int, simulations)
+	if remaining == 0 {
+		return weeks, nil
+	}
+	if sum(throughput) == 0 {
+		return nil, fmt.Errorf("no issue resolved in the last %d weeks, the completion cannot be forecast",
+			len(throughput))
+	}
+	for i := range weeks {
+		left := remaining
+		for left > 0 && weeks[i] < maxForecastWeeks {
+			left -= throughput[rng.IntN(len(throughput))]
+			weeks[i]++
+		}
+	}
+	sort.Ints(weeks)
+	return weeks, nil
+}
+
+// completion returns the date by which the given percentage of the simulations are complete.
+func (f *forecast) completion(percent int) (time.Time, int) {
+	weeks := f.Weeks[min(len(f.Weeks)*percent/100, len(f.Weeks)-1)] //nolint:mnd
+	return f.Today.AddDate(0, 0, weeks*daysPerWeek), weeks
+}
+
+// onTime returns the number of simulations complete by GA.
+func (f *forecast) onTime() int {
+	return sort.Search(len(f.Weeks), func(i int) bool {
+		return f.Today.AddDate(0, 0, f.Weeks[i]*daysPerWeek).After(f.GA)
+	})
+}
+
+func (f *forecast) write(w io.Writer) error {
+	work := "issues"
+	switch {
+	case f.Epics > 0 && f.Childless > 0:
+		work = fmt.Sprintf("issues, children of %d epics or among %d issues without children", f.Epics, f.Childless)
+	case f.Epics > 0:
+		work = fmt.Sprintf("child issues of %d epics", f.Epics)
+	}
+	fmt.Fprintf(w, "<span style=\"background-color:black; color:white\">FORECAST</span>\n\n")
+	fmt.Fprintf(w, "- Remaining: %d %s\n", f.Remaining, work)
+	fmt.Fprintf(w, "- Throughput: %.1f issues per week over the last %d weeks (%s)\n",
+		float64(sum(f.Throughput))/float64(len(f.Throughput)), len(f.Throughput),
+		strings.Trim(intList(f.Throughput), "[]"))
+	for _, percent := range forecastPercentiles {
+		date, weeks := f.completion(percent)
+		fmt.Fprintf(w, "- P%d: %s (%d weeks)", percent, date.Format(time.DateOnly), weeks)
+		if weeks >= maxForecastWeeks {
+			fmt.Fprint(w, " or later")
+		}
+		fmt.Fprintln(w)
+	}
+	onTime := f.onTime()
+	percent := onTime * 100 / len(f.Weeks) //nolint:mnd
+	fmt.Fprintf(w, "- GA %s: %d%% chance to complete, from %d simulations\n", f.GA.Format(time.DateOnly),
+		percent, len(f.Weeks))
+
+	color := "red"
+	switch {
+	case percent >= onTimeGreen:
+		color = greenColor
+	case percent >= onTimeYellow:
+		color = yellowColor
+	}
+	gauge, err := generateGaugeDataURI(gaugeWidth, gaugeHeight, "on time", color, percent, 100) //nolint:mnd
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, gauge)
+	return nil
+}
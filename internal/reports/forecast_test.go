@@ -0,0 +1,147 @@
+package reports
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+)
+
+func TestSimulateCompletion(t *testing.T) {
+	tests := []struct {
+		name       string
+		remaining  int
+		throughput []int
+		want       []int
+		wantErr    bool
+	}{
+		{name: "nothing remaining", remaining: 0, throughput: []int{0, 0}, want: []int{0, 0, 0}},
+		{name: "constant throughput", remaining: 5, throughput: []int{2, 2, 2}, want: []int{3, 3, 3}},
+		{name: "seeded draws", remaining: 4, throughput: []int{0, 1, 4}, want: []int{1, 1, 2}},
+		{name: "nothing resolved", remaining: 3, throughput: []int{0, 0, 0}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			weeks, err := simulateCompletion(rand.New(rand.NewPCG(1, 2)), tt.remaining, tt.throughput, 3)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("simulateCompletion returned %v, want an error", weeks)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(weeks, tt.want) {
+				t.Errorf("weeks = %v, want %v", weeks, tt.want)
+			}
+		})
+	}
+
+	// The same seed gives the same simulations.
+	first, _ := simulateCompletion(rand.New(rand.NewPCG(7, 7)), 20, []int{0, 1, 3, 5}, 100)
+	second, _ := simulateCompletion(rand.New(rand.NewPCG(7, 7)), 20, []int{0, 1, 3, 5}, 100)
+	if !slices.Equal(first, second) {
+		t.Errorf("simulations with the same seed differ: %v and %v", first, second)
+	}
+	if !slices.IsSorted(first) {
+		t.Errorf("simulations are not sorted: %v", first)
+	}
+}
+
+func TestForecastCompletion(t *testing.T) {
+	f := &forecast{
+		Weeks: []int{1, 2, 2, 3, 4, 5, 6, 8, 10, 12},
+		Today: time.Date(2026, time.January, 5, 0, 0, 0, 0, time.UTC),
+		GA:    time.Date(2026, time.February, 2, 0, 0, 0, 0, time.UTC),
+	}
+	for _, tt := range []struct {
+		percent int
+		weeks   int
+		date    string
+	}{
+		{percent: 0, weeks: 1, date: "2026-01-12"},
+		{percent: 50, weeks: 5, date: "2026-02-09"},
+		{percent: 85, weeks: 10, date: "2026-03-16"},
+		{percent: 100, weeks: 12, date: "2026-03-30"},
+	} {
+		date, weeks := f.completion(tt.percent)
+		if weeks != tt.weeks || date.Format(time.DateOnly) != tt.date {
+			t.Errorf("completion(%d) = %s, %d weeks, want %s, %d weeks", tt.percent, date.Format(time.DateOnly),
+				weeks, tt.date, tt.weeks)
+		}
+	}
+	// The simulations of 4 weeks or less complete on or before GA.
+	if got := f.onTime(); got != 5 {
+		t.Errorf("onTime() = %d, want 5", got)
+	}
+}
+
+func TestFetchForecastWork(t *testing.T) {
+	issue := func(key string, parent string) map[string]any {
+		fields := map[string]any{"summary": key}
+		if parent != "" {
+			fields["parent"] = map[string]string{"key": parent}
+		}
+		return map[string]any{"key": key, "fields": fields}
+	}
+	all := map[string]map[string]any{
+		"CNF-1":  issue("CNF-1", ""),
+		"CNF-2":  issue("CNF-2", ""),
+		"CNF-3":  issue("CNF-3", "CNF-1"),
+		"CNF-11": issue("CNF-11", "CNF-1"),
+		"CNF-12": issue("CNF-12", "CNF-1"),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rest/api/2/field":
+			_ = json.NewEncoder(w).Encode([]map[string]any{})
+		case "/rest/api/2/search":
+			jql := r.URL.Query().Get("jql")
+			keys := strings.Split(jql[strings.Index(jql, "(")+1:strings.Index(jql, ")")], ", ")
+			var issues []map[string]any
+			for _, key := range slices.Sorted(maps.Keys(all)) {
+				parent, _ := all[key]["fields"].(map[string]any)["parent"].(map[string]string)
+				if strings.HasPrefix(jql, "parent in") && slices.Contains(keys, parent["key"]) ||
+					strings.HasPrefix(jql, "key in") && slices.Contains(keys, key) {
+					issues = append(issues, all[key])
+				}
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"startAt": 0, "maxResults": len(issues),
+				"total": len(issues), "issues": issues})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	client, err := jira.NewClient(server.URL, server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// CNF-1 is an epic, CNF-2 has no children and CNF-3 is both in the query and a child of CNF-1.
+	query := []jira.Issue{{Key: "CNF-1"}, {Key: "CNF-2"}, {Key: "CNF-3"}}
+	work, epics, childless, err := fetchForecastWork(context.Background(), client, query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	for i := range work {
+		keys = append(keys, work[i].Key)
+	}
+	slices.Sort(keys)
+	if want := []string{"CNF-11", "CNF-12", "CNF-2", "CNF-3"}; !slices.Equal(keys, want) {
+		t.Errorf("work = %v, want %v", keys, want)
+	}
+	if epics != 1 || childless != 1 {
+		t.Errorf("epics, childless = %d, %d, want 1, 1", epics, childless)
+	}
+}
@@ -0,0 +1,280 @@
+package reports
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+	"github.com/edcdavid/jira-helper/internal/jirahelper"
+)
+
+const (
+	targetStartFieldName = "Target start"
+	targetEndFieldName   = "Target end"
+
+	ganttMermaid = "mermaid"
+	ganttHTML    = "html"
+)
+
+//go:embed templates/gantt.html.tmpl
+var ganttHTMLTemplate string
+
+// GanttOptions configures GetGanttReport.
+type GanttOptions struct {
+	JiraURL             string
+	PersonalAccessToken string
+	FilterQuery         string
+	Release             string
+	CustomerFacing      string
+	// ReleaseDate is the release cutoff, the latest fixVersion release date of the issues when
+	// empty.
+	ReleaseDate string
+	// Format is mermaid or html.
+	Format string
+}
+
+// ganttBar is the timeline of one issue.
+type ganttBar struct {
+	Key     string    `json:"key"`
+	Summary string    `json:"summary"`
+	URL     string    `json:"url"`
+	Color   string    `json:"color"`
+	Status  string    `json:"status"`
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	Done    bool      `json:"done"`
+	// Open is set when the issue has no target end and is not resolved: the bar ends today.
+	Open bool `json:"open"`
+	// Late is set when the issue is not done and its end has passed or is after the cutoff.
+	Late bool `json:"late"`
+}
+
+// ganttChart is the data of the Gantt chart.
+type ganttChart struct {
+	Title   string     `json:"title"`
+	Today   time.Time  `json:"today"`
+	Cutoff  *time.Time `json:"cutoff,omitempty"`
+	Bars    []ganttBar `json:"bars"`
+	Skipped int        `json:"-"`
+}
+
+// GetGanttReport prints the timeline of the issues of the report query as a Mermaid gantt
+// block or as an interactive echarts HTML page.
+func GetGanttReport(opts GanttOptions) {
+	initLog()
+	if opts.Format != ganttMermaid && opts.Format != ganttHTML {
+		log.Fatalf("Unknown gantt format %q, use %s or %s", opts.Format, ganttMermaid, ganttHTML)
+	}
+	filterQuery, err := ReportQuery(&ReportOptions{
+		FilterQuery: opts.FilterQuery, Release: opts.Release, CustomerFacing: opts.CustomerFacing,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	client, err := jirahelper.NewClient(opts.JiraURL, opts.PersonalAccessToken)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fieldIDs, err := jirahelper.FieldIDsByName(context.TODO(), client)
+	if err != nil {
+		log.Fatal(err)
+	}
+	issues, err := jirahelper.FetchAllIssues(context.TODO(), client, filterQuery, maxIssuesRetrieved)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	chart, err := buildGanttChart(issues, fieldIDs, &opts, time.Now())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if opts.Format == ganttHTML {
+		err = writeGanttHTML(os.Stdout, chart)
+	} else {
+		writeGanttMermaid(os.Stdout, chart)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// buildGanttChart computes the bar of each issue: from the target start, or the creation, to
+// the target end, or the resolution, or today while the issue is open.
+func buildGanttChart(issues []jira.Issue, fieldIDs map[string]string, opts *GanttOptions, now time.Time) (*ganttChart, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	chart := &ganttChart{Title: "Release " + opts.Release + " timeline", Today: today}
+	if opts.FilterQuery != "" {
+		chart.Title = "Timeline"
+	}
+	if opts.ReleaseDate != "" {
+		cutoff, err := time.Parse(time.DateOnly, opts.ReleaseDate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid release date %q: %w", opts.ReleaseDate, err)
+		}
+		chart.Cutoff = &cutoff
+	}
+
+	for i := range issues {
+		issue := &issues[i]
+		if opts.ReleaseDate == "" {
+			for _, version := range issue.Fields.FixVersions {
+				date, err := time.Parse(time.DateOnly, version.ReleaseDate)
+				if err == nil && (chart.Cutoff == nil || date.After(*chart.Cutoff)) {
+					chart.Cutoff = &date
+				}
+			}
+		}
+
+		bar := ganttBar{
+			Key:     issue.Key,
+			Summary: issue.Fields.Summary,
+			URL:     issueURL(opts.JiraURL, issue.Key),
+			Color:   getCustomField(colorField, issue),
+		}
+		if issue.Fields.Status != nil {
+			bar.Status = issue.Fields.Status.Name
+			bar.Done = issue.Fields.Status.StatusCategory.Key == jira.StatusCategoryComplete
+		}
+		start, ok := fieldDate(issue, targetStartFieldName, fieldIDs, now)
+		if !ok {
+			start, ok = fieldDate(issue, "created", fieldIDs, now)
+		}
+		if !ok {
+			chart.Skipped++
+			continue
+		}
+		end, ok := fieldDate(issue, targetEndFieldName, fieldIDs, now)
+		if !ok {
+			end, ok = fieldDate(issue, "resolved", fieldIDs, now)
+		}
+		if !ok {
+			end, bar.Open = today, true
+		}
+		bar.Start, bar.End = dateOnly(start), dateOnly(end)
+		if bar.End.Before(bar.Start) {
+			bar.End = bar.Start
+		}
+		chart.Bars = append(chart.Bars, bar)
+	}
+
+	for i := range chart.Bars {
+		bar := &chart.Bars[i]
+		bar.Late = !bar.Done && (!bar.Open && bar.End.Before(today) || chart.Cutoff != nil && bar.End.After(*chart.Cutoff))
+	}
+	sort.SliceStable(chart.Bars, func(i, j int) bool {
+		if !chart.Bars[i].Start.Equal(chart.Bars[j].Start) {
+			return chart.Bars[i].Start.Before(chart.Bars[j].Start)
+		}
+		return chart.Bars[i].Key < chart.Bars[j].Key
+	})
+	return chart, nil
+}
+
+func dateOnly(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// writeGanttMermaid writes a Mermaid gantt block with one section per color. Late issues are
+// critical tasks, done issues done tasks and the release cutoff a milestone.
+func writeGanttMermaid(w io.Writer, chart *ganttChart) {
+	fmt.Fprintln(w, "```mermaid")
+	fmt.Fprintln(w, "gantt")
+	fmt.Fprintf(w, "  title %s\n", mermaidTask(chart.Title))
+	fmt.Fprintln(w, "  dateFormat YYYY-MM-DD")
+	fmt.Fprintf(w, "  axisFormat %s\n", "%m/%d")
+	fmt.Fprintln(w, "  todayMarker stroke-width:3px,stroke:#015CE6")
+	for _, section := range []string{colorRed, colorYellow, colorGreen, noColor} {
+		started := false
+		for _, bar := range chart.Bars {
+			if ganttSection(bar.Color) != section {
+				continue
+			}
+			if !started {
+				fmt.Fprintf(w, "  section %s\n", section)
+				started = true
+			}
+			var tags []string
+			switch {
+			case bar.Done:
+				tags = append(tags, "done")
+			case bar.Late:
+				tags = append(tags, "crit", "active")
+			default:
+				tags = append(tags, "active")
+			}
+			tags = append(tags, strings.ReplaceAll(bar.Key, "-", "_"),
+				bar.Start.Format(time.DateOnly), bar.End.AddDate(0, 0, 1).Format(time.DateOnly))
+			fmt.Fprintf(w, "  %s %s :%s\n", bar.Key, mermaidTask(truncateRunes(bar.Summary, 40)), //nolint:mnd
+				strings.Join(tags, ", "))
+		}
+	}
+	if chart.Cutoff != nil {
+		fmt.Fprintln(w, "  section Release")
+		fmt.Fprintf(w, "  Cutoff :milestone, cutoff, %s, 0d\n", chart.Cutoff.Format(time.DateOnly))
+	}
+	fmt.Fprintln(w, "```")
+	late := 0
+	for _, bar := range chart.Bars {
+		if bar.Late {
+			late++
+		}
+	}
+	fmt.Fprintf(w, "\n%d issues, %d late", len(chart.Bars), late)
+	if chart.Skipped > 0 {
+		fmt.Fprintf(w, ", %d without dates", chart.Skipped)
+	}
+	fmt.Fprintln(w)
+}
+
+// ganttSection is the Mermaid section of a color: the health colors, and None for the others.
+func ganttSection(color string) string {
+	switch color {
+	case colorRed, colorYellow, colorGreen:
+		return color
+	default:
+		return noColor
+	}
+}
+
+// mermaidTask removes the characters Mermaid reads as task separators.
+func mermaidTask(text string) string {
+	return strings.NewReplacer(":", " ", "#", " ", ";", " ").Replace(text)
+}
+
+func truncateRunes(text string, length int) string {
+	runes := []rune(text)
+	if len(runes) <= length {
+		return text
+	}
+	return string(runes[:length-1]) + "…"
+}
+
+// writeGanttHTML writes a standalone HTML page drawing the chart with echarts: one bar per
+// issue filled with its color, late issues outlined and flagged, and lines for today and the
+// release cutoff.
+func writeGanttHTML(w io.Writer, chart *ganttChart) error {
+	data, err := json.Marshal(chart)
+	if err != nil {
+		return err
+	}
+	tmpl, err := template.New("gantt").Parse(ganttHTMLTemplate)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(w, map[string]any{
+		"Title":  chart.Title,
+		"Height": max(len(chart.Bars)*28+120, 300), //nolint:mnd
+		"Chart":  template.JS(data),                //nolint:gosec
+		"Colors": template.JS(fmt.Sprintf(`{"%s": "#D62728", "%s": %q, "%s": %q, "": "#AAAAAA"}`, //nolint:gosec
+			colorRed, colorYellow, yellowColor, colorGreen, "#3CB371")),
+	})
+}
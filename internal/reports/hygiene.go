@@ -0,0 +1,207 @@
+package reports
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+	"github.com/edcdavid/jira-helper/internal/jirahelper"
+	"github.com/edcdavid/jira-helper/internal/statussummary"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed rules/hygiene.yml
+var hygieneRulesYAML []byte
+
+// HygieneOptions configures GetHygieneReport.
+type HygieneOptions struct {
+	JiraURL             string
+	PersonalAccessToken string
+	FilterQuery         string
+	Release             string
+	CustomerFacing      string
+	// RulesFile replaces the embedded rules when set.
+	RulesFile string
+	// Format is markdown or json.
+	Format string
+}
+
+type HygieneRule struct {
+	Name        string              `yaml:"name" json:"name"`
+	Description string              `yaml:"description" json:"description"`
+	When        map[string][]string `yaml:"when" json:"-"`
+	Empty       []string            `yaml:"empty" json:"-"`
+	OlderThan   *AgeCheck           `yaml:"olderThan" json:"-"`
+}
+
+type AgeCheck struct {
+	Field string `yaml:"field"`
+	Days  int    `yaml:"days"`
+}
+
+type hygieneViolation struct {
+	Key     string `json:"key"`
+	Summary string `json:"summary"`
+	URL     string `json:"url"`
+	Detail  string `json:"detail"`
+}
+
+type hygieneRuleResult struct {
+	HygieneRule
+	Violations []hygieneViolation `json:"violations"`
+}
+
+// GetHygieneReport checks the issues of the report query against the hygiene rules, prints
+// the violations per rule and returns their number.
+func GetHygieneReport(opts HygieneOptions) int {
+	initLog()
+	rulesYAML := hygieneRulesYAML
+	if opts.RulesFile != "" {
+		var err error
+		rulesYAML, err = os.ReadFile(opts.RulesFile)
+		if err != nil {
+			log.Fatalf("Cannot read rules file %s, err:%v", opts.RulesFile, err)
+		}
+	}
+	var rules []HygieneRule
+	if err := yaml.Unmarshal(rulesYAML, &rules); err != nil {
+		log.Fatalf("Cannot load hygiene rules, err:%v", err)
+	}
+
+	client, err := jirahelper.NewClient(opts.JiraURL, opts.PersonalAccessToken)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fieldIDs, err := jirahelper.FieldIDsByName(context.TODO(), client)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	filterQuery := opts.FilterQuery
+	if filterQuery == "" {
+		filterQuery = GetFilterFromRelease(opts.Release, opts.CustomerFacing)
+	}
+	issues, err := jirahelper.FetchAllIssues(context.TODO(), client, filterQuery, maxIssuesRetrieved)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	results, total := checkHygiene(opts.JiraURL, rules, issues, fieldIDs, time.Now())
+	switch opts.Format {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(results); err != nil {
+			log.Fatal(err)
+		}
+	default:
+		writeHygieneMarkdown(os.Stdout, results, len(issues), total)
+	}
+	return total
+}
+
+func checkHygiene(jiraURL string, rules []HygieneRule, issues []jira.Issue, fieldIDs map[string]string,
+	now time.Time) (results []hygieneRuleResult, total int) {
+	for _, rule := range rules {
+		result := hygieneRuleResult{HygieneRule: rule, Violations: []hygieneViolation{}}
+		for i := range issues {
+			issue := &issues[i]
+			if !rule.applies(issue, fieldIDs) {
+				continue
+			}
+			if detail, violated := rule.check(issue, fieldIDs, now); violated {
+				result.Violations = append(result.Violations, hygieneViolation{
+					Key:     issue.Key,
+					Summary: issue.Fields.Summary,
+					URL:     issueURL(jiraURL, issue.Key),
+					Detail:  detail,
+				})
+			}
+		}
+		total += len(result.Violations)
+		results = append(results, result)
+	}
+	return results, total
+}
+
+func (r *HygieneRule) applies(issue *jira.Issue, fieldIDs map[string]string) bool {
+	for field, accepted := range r.When {
+		values := fieldValues(issue, field, fieldIDs)
+		if !slices.ContainsFunc(values, func(value string) bool {
+			return slices.ContainsFunc(accepted, func(a string) bool { return strings.EqualFold(a, value) })
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+// check returns whether the issue violates the rule, with a short explanation.
+func (r *HygieneRule) check(issue *jira.Issue, fieldIDs map[string]string, now time.Time) (string, bool) {
+	if len(r.Empty) == 0 && r.OlderThan == nil {
+		return "", true
+	}
+
+	var missing []string
+	for _, field := range r.Empty {
+		if len(fieldValues(issue, field, fieldIDs)) == 0 {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		return "missing " + strings.Join(missing, ", "), true
+	}
+
+	if r.OlderThan != nil {
+		date, ok := fieldDate(issue, r.OlderThan.Field, fieldIDs, now)
+		if ok && now.Sub(date) > time.Duration(r.OlderThan.Days)*hoursPerDay*time.Hour {
+			return fmt.Sprintf("%s dated %s, %d days ago", r.OlderThan.Field, date.Format(time.DateOnly),
+				int(now.Sub(date).Hours()/hoursPerDay)), true
+		}
+	}
+	return "", false
+}
+
+// fieldDate returns the date of a date field, or of the latest entry of the status summary.
+func fieldDate(issue *jira.Issue, field string, fieldIDs map[string]string, now time.Time) (time.Time, bool) {
+	if strings.EqualFold(field, "statusSummary") {
+		entry, ok := statussummary.Latest(getCustomField(statusSummaryField, issue), now)
+		return entry.Date, ok
+	}
+	values := fieldValues(issue, field, fieldIDs)
+	if len(values) == 0 {
+		return time.Time{}, false
+	}
+	return parseJiraDate(values[0])
+}
+
+func writeHygieneMarkdown(w io.Writer, results []hygieneRuleResult, issueCount, total int) {
+	fmt.Fprintf(w, "%d hygiene violations in %d issues\n\n", total, issueCount)
+	fmt.Fprintln(w, "| Rule | Description | Violations |")
+	fmt.Fprintln(w, "|---|---|---|")
+	for _, result := range results {
+		fmt.Fprintf(w, "| %s | %s | %d |\n", result.Name, result.Description, len(result.Violations))
+	}
+
+	for _, result := range results {
+		if len(result.Violations) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n**%s**: %s\n", result.Name, result.Description)
+		for _, violation := range result.Violations {
+			fmt.Fprintf(w, "  - [%s: %s](%s)", violation.Key, violation.Summary, violation.URL)
+			if violation.Detail != "" {
+				fmt.Fprintf(w, " - %s", violation.Detail)
+			}
+			fmt.Fprintln(w)
+		}
+	}
+}
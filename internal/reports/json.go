@@ -0,0 +1,86 @@
+package reports
+
+import (
+	"time"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+)
+
+// noColor is the color key of issues without a health color.
+const noColor = "None"
+
+// ReportSummary is the machine readable form of the markdown report.
+type ReportSummary struct {
+	Release        string         `json:"release,omitempty"`
+	CustomerFacing string         `json:"customerFacing,omitempty"`
+	Filter         string         `json:"filter,omitempty"`
+	GeneratedAt    time.Time      `json:"generatedAt"`
+	Total          int            `json:"total"`
+	Colors         map[string]int `json:"colors"`
+	Statuses       map[string]int `json:"statuses"`
+	Stale          int            `json:"stale"`
+	Issues         []ReportIssue  `json:"issues"`
+}
+
+// ReportIssue is one issue of a ReportSummary.
+type ReportIssue struct {
+	Key               string     `json:"key"`
+	Summary           string     `json:"summary"`
+	URL               string     `json:"url"`
+	Color             string     `json:"color"`
+	Status            string     `json:"status"`
+	Components        []string   `json:"components,omitempty"`
+	StatusSummary     string     `json:"statusSummary,omitempty"`
+	StatusSummaryDate *time.Time `json:"statusSummaryDate,omitempty"`
+	Stale             bool       `json:"stale"`
+}
+
+// BuildReportSummary counts the issues of a report by color and status. Issues without a
+// color are counted under "None".
+func BuildReportSummary(issues []jira.Issue, opts *ReportOptions, now time.Time) ReportSummary {
+	summary := ReportSummary{
+		Release:        opts.Release,
+		CustomerFacing: opts.CustomerFacing,
+		Filter:         opts.FilterQuery,
+		GeneratedAt:    now,
+		Total:          len(issues),
+		Colors:         map[string]int{colorGreen: 0, colorYellow: 0, colorRed: 0, noColor: 0},
+		Statuses:       map[string]int{},
+		Issues:         []ReportIssue{},
+	}
+	for i := range issues {
+		issue := &issues[i]
+		color := getCustomField(colorField, issue)
+		if color == "" {
+			color = noColor
+		}
+		status := ""
+		if issue.Fields.Status != nil {
+			status = issue.Fields.Status.Name
+		}
+		var components []string
+		for _, component := range issue.Fields.Components {
+			components = append(components, component.Name)
+		}
+		reportIssue := ReportIssue{
+			Key:           issue.Key,
+			Summary:       issue.Fields.Summary,
+			URL:           issueURL(opts.JiraURL, issue.Key),
+			Color:         color,
+			Status:        status,
+			Components:    components,
+			StatusSummary: getCustomField(statusSummaryField, issue),
+		}
+		if date, dated := StatusSummaryDate(issue, now); dated {
+			reportIssue.StatusSummaryDate = &date
+			reportIssue.Stale = isStale(color, date, dated, opts.StaleAfter, now)
+		}
+		if reportIssue.Stale {
+			summary.Stale++
+		}
+		summary.Colors[color]++
+		summary.Statuses[status]++
+		summary.Issues = append(summary.Issues, reportIssue)
+	}
+	return summary
+}
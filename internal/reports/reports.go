@@ -5,23 +5,28 @@ import (
 	"context"
 	_ "embed"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
-	"net/http"
 	"os"
 	"time"
-	"unicode"
 
 	"regexp"
+	"slices"
 	"sort"
 	"strconv"
 
 	"encoding/base64"
 
 	jira "github.com/andygrunwald/go-jira/v2/cloud"
+	"github.com/edcdavid/jira-helper/internal/issuestore"
 	"github.com/edcdavid/jira-helper/internal/jirahelper"
+	"github.com/edcdavid/jira-helper/internal/ollamahelper"
+	"github.com/edcdavid/jira-helper/internal/prompts"
+	"github.com/edcdavid/jira-helper/internal/statussummary"
 	"github.com/edcdavid/jira-helper/internal/stringhelper"
-	"github.com/ollama/ollama/api"
+	"github.com/edcdavid/jira-helper/internal/timehelper"
 	"github.com/schollz/progressbar/v3"
 	"gopkg.in/yaml.v3"
 
@@ -53,24 +58,15 @@ const (
 	greenColor  = "#00FF00"
 	yellowColor = "#E6B800"
 	blueColor   = "#015CE6"
+	staleColor  = "#8B4513"
 
 	yes  = "yes"
 	no   = "no"
 	both = "both"
 
-	aiSeed = 42
-
 	logFileName = "jira-helper.log"
 )
 
-var (
-	FALSE = false
-	TRUE  = true
-)
-
-type patTransport struct {
-	Token string
-}
 type jiraColor struct {
 	Disabled bool   `json:"disabled"`
 	ID       string `json:"id"`
@@ -116,11 +112,60 @@ type jiraState struct {
 	Self string `json:"self"`
 }
 
+// ReportOptions configures GetMarkdownReport.
+type ReportOptions struct {
+	JiraURL             string
+	PersonalAccessToken string
+	FilterQuery         string
+	Release             string
+	CustomerFacing      string
+	OllamaModel         string
+	ShowOriginalStatus  bool
+	// FormatPrompt is an optional prompt template file overriding the embedded format status prompt.
+	FormatPrompt string
+	// Summary adds an AI executive summary of the red and yellow issues at the top of the report.
+	Summary bool
+	// SummaryPrompt is an optional prompt template file overriding the embedded one.
+	SummaryPrompt string
+	// SuggestColor adds a suggested health color to the issues without one.
+	SuggestColor bool
+	// SuggestStaleDays is the number of days without update after which an issue is at risk.
+	SuggestStaleDays int
+	// SuggestWithAI asks the Ollama model to refine the heuristic color suggestion.
+	SuggestWithAI bool
+	// SuggestPrompt is an optional prompt template file overriding the embedded one.
+	SuggestPrompt string
+	// StaleAfter moves the red and yellow issues whose latest status entry is older into a
+	// stale section. Zero disables the stale detection.
+	StaleAfter time.Duration
+	// FromStore is the issue store file of the listen command. When set, the issues are read
+	// from it instead of Jira.
+	FromStore string
+	// Rollup fetches the child issues of each epic and shows their completion.
+	Rollup bool
+	// Milestones is the release of the release calendar, for the milestone countdown and the
+	// issues behind the upcoming milestone. Nil when the release is not in the calendar.
+	Milestones *Release
+}
+
 type JiraFilter struct {
 	Name      string `yaml:"name"`
 	URL       string `yaml:"url"`
 	Variables int    `yaml:"variables"`
 	Filter    string `yaml:"filter"`
+	// Trend is the query of all the issues the filter follows, whatever their status, from which
+	// the weekly flow of its queue is computed. It takes the same variables as Filter.
+	Trend string `yaml:"trend"`
+	// ExcludedStatuses are the statuses the filter leaves out: an issue of the trend query
+	// leaves the queue when it moves to one of them, as when it is resolved.
+	ExcludedStatuses []string `yaml:"excludedStatuses"`
+	// GroupBy is the field the issues are counted by, component when empty: one of the fieldValues
+	// aliases, a custom field id or a field name.
+	GroupBy string `yaml:"groupBy"`
+	// StackBy splits the bar of each group by the values of a second field.
+	StackBy string `yaml:"stackBy"`
+	// Thresholds give the RAG status of the filter, it has none when they are not set.
+	Thresholds *BugStatusThresholds `yaml:"thresholds"`
 }
 
 //go:embed filters/bugstatus.yml
@@ -132,6 +177,7 @@ type stats struct {
 	colorYellow   int
 	colorNoStatus int
 	colorTotal    int
+	stale         int
 
 	statusClosed         int
 	statusReleasePending int
@@ -142,12 +188,8 @@ type stats struct {
 	statusPlaning        int
 }
 
-func (t *patTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-	req.Header.Set("Authorization", "Bearer "+t.Token)
-	return http.DefaultTransport.RoundTrip(req)
-}
-
-func getFilterFromRelease(release, customerFacing string) string {
+// GetFilterFromRelease returns the JQL of the telco epics of a release.
+func GetFilterFromRelease(release, customerFacing string) string {
 	switch customerFacing {
 	case yes:
 		return `(project = "Cloud-native Network Functions" and issuetype = epic or project = "OpenShift Edge Enablement" and "Portfolio Solutions" = Telco or project = "KNI QE - System Test" and "Portfolio Solutions" = Telco and issuetype = epic and status not in (Obsolete, "Won't Fix / Obsolete", "Won't Do", "WON'T FIX", "Won't Fix / Duplicate", WONTFIX) or issue = OCPNODE-2305) and issuetype = epic and fixVersion = openshift-` + release + ` and Planning = "Customer Facing"` //nolint:lll
@@ -173,56 +215,140 @@ func initLog() {
 	log.SetOutput(file)
 }
 
-func GetMarkdownReport(jiraURL, personalAccessToken, filterQuery, release, customerFacing, ollamaModel string, showOriginalStatus bool) { //nolint:funlen
+func GetMarkdownReport(opts ReportOptions) {
 	initLog()
-	httpClient := &http.Client{
-		Transport: &patTransport{Token: personalAccessToken},
+	if err := WriteMarkdownReport(context.TODO(), os.Stdout, &opts); err != nil {
+		log.Fatal(err)
 	}
+}
 
-	client, err := jira.NewClient(jiraURL, httpClient)
+// WriteMarkdownReport fetches the issues of the report query and writes the report to w.
+func WriteMarkdownReport(ctx context.Context, w io.Writer, opts *ReportOptions) error {
+	if opts.Summary && opts.OllamaModel == "" {
+		return errors.New("an Ollama model is required to generate the executive summary")
+	}
+	if opts.SuggestWithAI && opts.OllamaModel == "" {
+		return errors.New("an Ollama model is required to suggest colors with AI")
+	}
+	if opts.Rollup && opts.FromStore != "" {
+		return errors.New("the epic rollup needs the child issues from Jira and cannot be used with the issue store")
+	}
+	issues, err := FetchReportIssues(ctx, opts)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
+	var rollups map[string]EpicRollup
+	if opts.Rollup {
+		client, err := jirahelper.NewClient(opts.JiraURL, opts.PersonalAccessToken)
+		if err != nil {
+			return err
+		}
+		rollups, err = FetchEpicRollups(ctx, client, issues)
+		if err != nil {
+			return err
+		}
+	}
+	return RenderMarkdownReport(w, issues, rollups, opts)
+}
 
-	statistics := stats{}
-	outputYellow := ""
-	outputRed := ""
-	outputGreen := ""
-	outputNone := ""
+// ReportQuery returns the query of the report: the filter query, or the query of the release.
+func ReportQuery(opts *ReportOptions) (string, error) {
+	if opts.FilterQuery != "" {
+		return opts.FilterQuery, nil
+	}
+	if !slices.Contains([]string{yes, no, both}, opts.CustomerFacing) {
+		return "", fmt.Errorf("customerFacing argument: %s not supported. Use %s, %s, or %s",
+			opts.CustomerFacing, yes, no, both)
+	}
+	return GetFilterFromRelease(opts.Release, opts.CustomerFacing), nil
+}
 
-	if filterQuery == "" {
-		filterQuery = getFilterFromRelease(release, customerFacing)
+// FetchReportIssues returns the issues of the report query, from Jira or from the issue store
+// maintained by the listen command.
+func FetchReportIssues(ctx context.Context, opts *ReportOptions) ([]jira.Issue, error) {
+	filterQuery, err := ReportQuery(opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.FromStore != "" {
+		issues, store, err := issuestore.LoadIssues(opts.FromStore, filterQuery)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Rendering %d issues from %s, last updated %s", len(issues), opts.FromStore, store.Updated)
+		return issues, nil
 	}
 
-	var issues []jira.Issue
-	issues, err = jirahelper.FetchAllIssues(context.TODO(), client, filterQuery, maxIssuesRetrieved)
+	client, err := jirahelper.NewClient(opts.JiraURL, opts.PersonalAccessToken)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
+	// The changelog dates the status of the issues without a dated entry, for the age labels.
+	return jirahelper.FetchAllIssuesExpanded(ctx, client, filterQuery, maxIssuesRetrieved, "changelog")
+}
+
+// RenderMarkdownReport writes the report of already fetched issues to w. The epic rollups are
+// optional.
+func RenderMarkdownReport(w io.Writer, issues []jira.Issue, rollups map[string]EpicRollup, opts *ReportOptions) error { //nolint:funlen,gocyclo
+	suggestModel := ""
+	if opts.SuggestWithAI {
+		suggestModel = opts.OllamaModel
+	}
+
+	statistics := stats{}
+	outputYellow := ""
+	outputRed := ""
+	outputGreen := ""
+	outputNone := ""
+	outputStale := ""
+	var summaryIssues []summaryIssue
+	behind := 0
 
+	now := time.Now()
 	progressBar := progressbar.NewOptions(len(issues),
 		progressbar.OptionEnableColorCodes(true),
 		progressbar.OptionSetWriter(os.Stderr),
-		progressbar.OptionSetDescription(fmt.Sprintf("Processing status summary with %s model ...", ollamaModel)))
+		progressbar.OptionSetDescription(fmt.Sprintf("Processing status summary with %s model ...", opts.OllamaModel)))
 
 	for _, issue := range issues {
 		_ = progressBar.Add(1)
 
-		color := getCustomField("customfield_12320845", &issue)
-		statusSummary := getCustomField("customfield_12320841",
-			&issue)
+		color := getCustomField(colorField, &issue)
+		statusSummary := getCustomField(statusSummaryField, &issue)
 		state := issue.Fields.Status.Name
 
-		output := fmt.Sprintf("  - [%s: %s](https://issues.redhat.com/browse/%s)\n",
-			issue.Key, issue.Fields.Summary, issue.Key)
+		statusDate, dated := StatusSummaryDate(&issue, now)
+		stale := isStale(color, statusDate, dated, opts.StaleAfter, now)
+		if stale {
+			statistics.stale++
+		}
+		ageLabel := ""
+		if color == colorRed || color == colorYellow {
+			movedFrom := ""
+			if stale {
+				movedFrom = color
+			}
+			ageLabel = statusAgeLabel(movedFrom, statusDate, dated, now)
+		}
+		if milestone := opts.Milestones.behind(&issue, now); milestone != nil {
+			behind++
+			ageLabel += behindLabel(milestone, now)
+		}
+
+		output := fmt.Sprintf("  - [%s: %s](%s)%s\n",
+			issue.Key, issue.Fields.Summary, issueURL(opts.JiraURL, issue.Key), ageLabel)
 
 		nbsp := "\u00A0" // Non-breaking space
 		re := regexp.MustCompile(`[\s\t\n\r` + regexp.QuoteMeta(nbsp) + `]+`)
 		cleaned := re.ReplaceAllString(statusSummary, "")
 
 		if cleaned != "" {
-			if ollamaModel != "" {
-				output = aiFormatStatus(statusSummary, output, ollamaModel)
+			if opts.OllamaModel != "" {
+				var err error
+				output, err = aiFormatStatus(statusSummary, output, opts.OllamaModel, opts.FormatPrompt)
+				if err != nil {
+					return err
+				}
 			} else {
 				// Add bullet
 				re = regexp.MustCompile(`\n+`)
@@ -235,18 +361,39 @@ func GetMarkdownReport(jiraURL, personalAccessToken, filterQuery, release, custo
 			}
 		}
 
+		if rollup, ok := rollups[issue.Key]; ok {
+			output += rollup.markdown()
+		}
+
 		switch color {
-		case "Green":
+		case colorGreen:
 			statistics.colorGreen++
 			outputGreen += output
-		case "Yellow":
+		case colorYellow:
 			statistics.colorYellow++
-			outputYellow += output
-		case "Red":
+			if stale {
+				outputStale += output
+			} else {
+				outputYellow += output
+			}
+			summaryIssues = append(summaryIssues, newSummaryIssue(&issue, color, statusSummary))
+		case colorRed:
 			statistics.colorRed++
-			outputRed += output
+			if stale {
+				outputStale += output
+			} else {
+				outputRed += output
+			}
+			summaryIssues = append(summaryIssues, newSummaryIssue(&issue, color, statusSummary))
 		default:
 			statistics.colorNoStatus++
+			if opts.SuggestColor {
+				suggestion, err := suggestColor(&issue, statusSummary, opts.SuggestStaleDays, suggestModel, opts.SuggestPrompt)
+				if err != nil {
+					return err
+				}
+				output += suggestion.markdown()
+			}
 			outputNone += output
 		}
 
@@ -270,17 +417,44 @@ func GetMarkdownReport(jiraURL, personalAccessToken, filterQuery, release, custo
 		}
 	}
 
-	statistics.colorTotal = statistics.colorGreen + statistics.colorRed + statistics.colorYellow + statistics.colorNoStatus
-	greenGauge := generateGaugeDataURI(gaugeWidth, gaugeHeight,
-		"green", greenColor, statistics.colorGreen, statistics.colorTotal)
-	redGauge := generateGaugeDataURI(gaugeWidth, gaugeHeight,
-		"red", "red", statistics.colorRed, statistics.colorTotal)
-	yellowGauge := generateGaugeDataURI(gaugeWidth, gaugeHeight,
-		"yellow", yellowColor, statistics.colorYellow, statistics.colorTotal)
-	noStatusGauge := generateGaugeDataURI(gaugeWidth, gaugeHeight,
-		"no status", "grey", statistics.colorNoStatus, statistics.colorTotal)
+	if opts.Milestones != nil {
+		fmt.Fprintln(w, opts.Milestones.milestonesMarkdown(behind, now))
+	}
 
-	fmt.Println("\n\n" + redGauge + yellowGauge + greenGauge + noStatusGauge)
+	if opts.Summary {
+		summary, err := aiExecutiveSummary(opts.JiraURL, summaryIssues, opts.OllamaModel, opts.SummaryPrompt)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, "<span style=\"background-color:black; color:white\">EXECUTIVE SUMMARY</span>\n\n"+summary)
+	}
+
+	statistics.colorTotal = statistics.colorGreen + statistics.colorRed + statistics.colorYellow + statistics.colorNoStatus
+	gauges := []struct {
+		label, color string
+		value        int
+	}{
+		{"red", "red", statistics.colorRed},
+		{"yellow", yellowColor, statistics.colorYellow},
+		{"green", greenColor, statistics.colorGreen},
+		{"no status", "grey", statistics.colorNoStatus},
+	}
+	if opts.StaleAfter > 0 {
+		gauges = append(gauges, struct {
+			label, color string
+			value        int
+		}{"stale", staleColor, statistics.stale})
+	}
+	gaugesOutput := "\n\n"
+	for _, gauge := range gauges {
+		dataURI, err := generateGaugeDataURI(gaugeWidth, gaugeHeight, gauge.label, gauge.color, gauge.value,
+			statistics.colorTotal)
+		if err != nil {
+			return err
+		}
+		gaugesOutput += dataURI
+	}
+	fmt.Fprintln(w, gaugesOutput)
 
 	labels := []string{"CLOSED", "RELEASE PENDING", "IN PROGRESS", "DEV COMPLETE", "PLANNING", "TO DO", "NEW"}
 	values := []int{statistics.statusClosed,
@@ -291,123 +465,185 @@ func GetMarkdownReport(jiraURL, personalAccessToken, filterQuery, release, custo
 		statistics.statusToDo,
 		statistics.statusNew,
 	}
-	bar := generateBarDataURI(barWidth, barHeight, labels, values)
-	fmt.Println(bar)
+	bar, err := generateBarDataURI(barWidth, barHeight, labels, values, sum(values))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, bar)
+
+	if rollups != nil {
+		completion, err := generateCompletionDataURI(issues, rollups)
+		if err != nil {
+			return err
+		}
+		if completion != "" {
+			fmt.Fprintln(w, "\n<span style=\"background-color:black; color:white\">RELEASE COMPLETION</span>\n\n"+
+				"_Child issues done (green), in progress (blue) and to do (grey); "+
+				"the percentage is the share of child issues done._"+completion)
+		}
+	}
 
 	finalOutput := fmt.Sprintf("<br>\n\n<span style=\"background-color:red; color:white\">RED</span>\n%s\n"+
 		"<span style=\"background-color:yellow; color:black\">YELLOW</span>\n%s\n"+
 		"<span style=\"background-color:grey; color:white\">NO STATUS</span>\n%s", outputRed, outputYellow, outputNone)
-	if !showOriginalStatus {
+	if rollups != nil {
+		// The green epics are only listed with their progress.
+		finalOutput += fmt.Sprintf("\n<span style=\"background-color:green; color:white\">GREEN</span>\n%s", outputGreen)
+	}
+	if opts.StaleAfter > 0 {
+		finalOutput += fmt.Sprintf("\n<span style=\"background-color:%s; color:white\">STALE (no status update in %d days)</span>\n%s",
+			staleColor, timehelper.Days(opts.StaleAfter), outputStale)
+	}
+	if !opts.ShowOriginalStatus {
 		finalOutput = stringhelper.StripMarkdownCodeBlocks(finalOutput)
 	}
-	fmt.Println(finalOutput)
+	fmt.Fprintln(w, finalOutput)
+	return nil
 }
 
-func aiFormatStatus(input, issueHeader, ollamaModel string) string { //nolint:funlen
-	input = strings.ReplaceAll(input, "\r", "")
-	input = strings.ReplaceAll(input, "\n\n", "\n")
-	input = strings.ReplaceAll(input, "\t", " ")
-	input = strings.ReplaceAll(input, " / ", "/")
-	// Create a new client
-	client, err := api.ClientFromEnvironment()
+func aiFormatStatus(input, issueHeader, ollamaModel, promptOverride string) (string, error) {
+	input = statussummary.Normalize(input)
+	systemPrompt, err := prompts.Render(prompts.FormatStatus, promptOverride, prompts.FormatStatusData{Year: time.Now().Year()})
 	if err != nil {
-		log.Fatalf("Failed to create Ollama client: %v", err)
+		return "", fmt.Errorf("cannot render format status prompt: %w", err)
 	}
 
-	// Define the chat request
-	chatReq := &api.ChatRequest{
-		Model: ollamaModel, // Replace with your desired model
-		Messages: []api.Message{
-			{
-				Role: "system",
-				//nolint:lll
-				Content: fmt.Sprintf(`Identify all status entries in the input. Each status begins with a date (which may or may not include a year) and ends either when the next status begins or at the end of the input. If a date is missing a year, assume it is %d.
-
-From all the statuses, extract only the most recent one by date.
-
-For the selected status:
-
-Remove all Atlassian-style wiki markup, including:
-
-Formatting such as *bold*, _italics_
-
-Headings like h1., h2., etc.
-
-Any list formatting such as lines starting with *, -, or +
-
-Remove any existing bullet points from the original content.
-
-Do not alter any words, phrases, punctuation, or sentence structure. Preserve the exact original wording.
-
-Split the cleaned content into logical bullet points, using one bullet per sentence or coherent chunk.
-
-Format the output as follows:
-
-Start with the date in this format: **MM/DD/YYYY**:
-
-Immediately after the colon (with no blank line), write each bullet point on a new line
-
-On the next lines, write each bullet point on its own line, with exactly 6 spaces of indentation before the dash (-), like this:
-      - This is a bullet point.
-
-Do not insert blank lines between bullet points. Every bullet should be on the next immediate line.
-
-After the bullet list, include the full original extracted status (before cleaning or splitting), inside a Markdown code block using triple backticks.
-At the top of the code block, include the full status date.
+	chatResp, err := ollamahelper.Chat(context.Background(), ollamaModel, systemPrompt, input)
+	if err != nil {
+		return "", fmt.Errorf("chat request failed: %w", err)
+	}
 
-Do not indent the code block or its contents.
+	chatResp += "\n"
+	return issueHeader + fmt.Sprintf("    - %s\n\n", chatResp), nil
+}
 
-Return only this formatted output. Do not include any additional text or explanation.`, time.Now().Year()),
-			},
-			{
-				Role:    "user",
-				Content: input,
-			},
-		},
-		Options: map[string]interface{}{
-			"seed": aiSeed,
-		},
-		Stream: &FALSE,
+// GetBugStatusReport prints the bug status report and returns the worst RAG status of the
+// filters, empty when none has thresholds.
+func GetBugStatusReport(jiraURL, personalAccessToken, releaseCutoffDate, fromDate string, listIssues bool) string {
+	rag, err := WriteBugStatusReport(context.TODO(), os.Stdout, jiraURL, personalAccessToken, releaseCutoffDate, fromDate,
+		listIssues)
+	if err != nil {
+		log.Fatal(err)
 	}
+	return rag
+}
 
-	// Send the chat request
-	var chatResp string
-	err = client.Chat(context.Background(), chatReq, func(resp api.ChatResponse) error {
-		chatResp = trimLeadingWhitespaceAndNewlines(removeThinkBlocks(resp.Message.Content))
-		return nil
-	})
+// WriteBugStatusReport writes the bar diagram of each bugstatus.yml filter to w, followed by
+// the issues of each group when listIssues is set. It returns the worst RAG status of the
+// filters.
+func WriteBugStatusReport(ctx context.Context, w io.Writer, jiraURL, personalAccessToken, releaseCutoffDate, fromDate string,
+	listIssues bool) (string, error) {
+	client, err := jirahelper.NewClient(jiraURL, personalAccessToken)
 	if err != nil {
-		log.Fatalf("Chat request failed: %v", err)
+		return "", err
 	}
-
-	// Print the response
-	log.Println("Original:", input)
-	log.Println("Model response:", chatResp)
-	log.Println("------------------------------------------")
-
-	chatResp += "\n"
-	return issueHeader + fmt.Sprintf("    - %s\n\n", chatResp)
-}
-func trimLeadingWhitespaceAndNewlines(s string) string {
-	return strings.TrimLeftFunc(s, unicode.IsSpace)
+	counts, err := FetchBugStatusCounts(ctx, client, releaseCutoffDate, fromDate)
+	if err != nil {
+		return "", err
+	}
+	if err := RenderBugStatusReport(w, counts, jiraURL, listIssues); err != nil {
+		return "", err
+	}
+	return WorstRAG(counts), nil
 }
-func removeThinkBlocks(input string) string {
-	re := regexp.MustCompile(`(?s)<think>.*?</think>`)
-	return re.ReplaceAllString(input, "")
+
+// BugStatusCount is the number of issues per group matched by one bugstatus.yml filter.
+type BugStatusCount struct {
+	Name    string
+	URL     string
+	GroupBy string
+	// StackBy is empty when the bars are not stacked.
+	StackBy string
+	// Groups is the number of issues per value of GroupBy. An issue with several values, as
+	// several components, is counted in each of their groups.
+	Groups map[string]int
+	// Stacks is the number of issues per value of StackBy in each group, and StackTotals over
+	// all the issues.
+	Stacks      map[string]map[string]int
+	StackTotals map[string]int
+	// Total is the number of distinct issues.
+	Total int
+	// OldestDays is the age of the oldest issue.
+	OldestDays int
+	// RAG is the color given by the thresholds of the filter, empty when it has none.
+	RAG string
+	// issues are listed under the chart on demand.
+	issues []bugStatusIssue
+	// Trend is nil when the filter has no trend query.
+	Trend *BugTrend
 }
-func GetBugStatusReport(jiraURL, personalAccessToken, releaseCutoffDate, fromDate string) {
+
+// FetchBugStatusCounts counts the issues per group of each bugstatus.yml filter.
+func FetchBugStatusCounts(ctx context.Context, client *jira.Client, releaseCutoffDate, fromDate string) ([]BugStatusCount, error) {
 	filters, err := loadFilters(bugStatusFiltersYAML)
 	if err != nil {
-		log.Fatalf("Cannot load embedded filters, err:%v", err)
+		return nil, fmt.Errorf("cannot load embedded filters, err:%w", err)
+	}
+	var fieldIDs map[string]string
+	for _, filter := range filters {
+		if filter.GroupBy != "" || filter.StackBy != "" {
+			// Field names are only needed to group by custom fields.
+			fieldIDs, err = jirahelper.FieldIDsByName(ctx, client)
+			if err != nil {
+				return nil, err
+			}
+			break
+		}
 	}
+	var counts []BugStatusCount
 	for _, filter := range filters {
-		allVariables := []string{fromDate, releaseCutoffDate, releaseCutoffDate}
-		patchedFilter := fmt.Sprintf(filter.Filter, toAnySliceNFirst(allVariables, filter.Variables)...)
+		patchedFilter := patchFilter(filter, releaseCutoffDate, fromDate)
 
-		bar := getBugStatusDiagram(jiraURL, personalAccessToken, patchedFilter, bugStatusWidth, bugStatusHeight)
-		fmt.Println("\n\n- [" + filter.Name + "](" + filter.URL + ")" + "\n" + bar)
+		count, err := getBugStatusCounts(ctx, client, patchedFilter, filter, fieldIDs)
+		if err != nil {
+			return nil, err
+		}
+		if filter.Trend != "" {
+			trendFilter := patchFilter(JiraFilter{Filter: filter.Trend, Variables: filter.Variables}, releaseCutoffDate, fromDate)
+			count.Trend, err = FetchBugTrend(ctx, client, trendFilter, filter.ExcludedStatuses, releaseCutoffDate, fromDate,
+				time.Now())
+			if err != nil {
+				return nil, err
+			}
+		}
+		counts = append(counts, count)
+	}
+	return counts, nil
+}
+
+// RenderBugStatusReport writes a summary table of the filters to w, then the bar diagram of
+// each filter count with its RAG badge, followed by its created versus resolved trend when the
+// filter has one, and by the issues of each group when listIssues is set.
+func RenderBugStatusReport(w io.Writer, counts []BugStatusCount, jiraURL string, listIssues bool) error {
+	now := time.Now()
+	writeBugStatusSummary(w, counts)
+	for i := range counts {
+		count := &counts[i]
+		bar, err := count.barDataURI()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, "\n\n- ["+count.Name+"]("+count.URL+")"+ragBadge(count.RAG)+count.caption()+"\n"+bar)
+		if count.Trend != nil {
+			trend, err := count.Trend.markdown()
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(w, "\n"+trend)
+		}
+		if listIssues {
+			fmt.Fprint(w, count.issueLists(jiraURL, now))
+		}
 	}
+	return nil
+}
+
+// patchFilter fills the date variables of a bugstatus.yml filter.
+func patchFilter(filter JiraFilter, releaseCutoffDate, fromDate string) string {
+	allVariables := []string{fromDate, releaseCutoffDate, releaseCutoffDate}
+	return fmt.Sprintf(filter.Filter, toAnySliceNFirst(allVariables, filter.Variables)...)
 }
+
 func toAnySliceNFirst(slice []string, n int) []any {
 	result := make([]any, len(slice))
 	for i, v := range slice {
@@ -418,32 +654,20 @@ func toAnySliceNFirst(slice []string, n int) []any {
 	}
 	return result[:n]
 }
-func getBugStatusDiagram(jiraURL, personalAccessToken, filterQuery string, width, height int) string {
-	httpClient := &http.Client{
-		Transport: &patTransport{Token: personalAccessToken},
-	}
-
-	client, err := jira.NewClient(jiraURL, httpClient)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	var issues []jira.Issue
-	issues, err = jirahelper.FetchAllIssues(context.TODO(), client, filterQuery, maxIssuesRetrieved)
+func getBugStatusCounts(ctx context.Context, client *jira.Client, filterQuery string, filter JiraFilter,
+	fieldIDs map[string]string) (BugStatusCount, error) {
+	issues, err := jirahelper.FetchAllIssues(ctx, client, filterQuery, maxIssuesRetrieved)
 	if err != nil {
-		log.Fatal(err)
+		return BugStatusCount{}, err
 	}
-	componentsMap := map[string]int{}
-
-	for _, issue := range issues {
-		components := issue.Fields.Components
-		for _, component := range components {
-			componentsMap[component.Name]++
-		}
+	count := BugStatusCount{Name: filter.Name, URL: filter.URL, GroupBy: filter.GroupBy, StackBy: filter.StackBy}
+	if count.GroupBy == "" {
+		count.GroupBy = DefaultGroupBy
 	}
-
-	keys, values := getKeyValueFromMap(componentsMap)
-	return generateBarDataURI(width, height, keys, values)
+	count.countIssues(issues, fieldIDs)
+	count.OldestDays = count.oldestDays(time.Now())
+	count.RAG = filter.Thresholds.rag(count.Total, count.OldestDays)
+	return count, nil
 }
 
 func getKeyValueFromMap(aMap map[string]int) (keys []string, values []int) {
@@ -486,7 +710,7 @@ func getCustomField(name string, issue *jira.Issue) string {
 			return str
 		}
 		switch name {
-		case "customfield_12320845":
+		case colorField:
 			aJson, err := json.MarshalIndent(value, "", "  ")
 			if err != nil {
 				return ""
@@ -536,11 +760,11 @@ func sVGStringToPNGDataURI(svgSrc string, quality, width, height int) (string, e
 	return fmt.Sprintf("\n\n<img src=\"data:image/jpeg;base64,%s\" width=\"%d\" height=\"%d\">", b64, width, height), nil
 }
 
-func generateBarDataURI(width, height int, labels []string, values []int) string {
-	total := 0
+// generateBarDataURI draws one bar per label with its share of the total, after a TOTAL bar.
+// The total is given as the values may count an issue more than once.
+func generateBarDataURI(width, height int, labels []string, values []int, total int) (string, error) {
 	valueStrings := []string{}
 	for _, v := range values {
-		total += v
 		valueStrings = append(valueStrings, strconv.Itoa(v))
 	}
 	valueStrings = append([]string{strconv.Itoa(total)}, valueStrings...)
@@ -572,18 +796,21 @@ func generateBarDataURI(width, height int, labels []string, values []int) string
 		fmt.Sprintf("[%s]", strings.Join(renderedValues, ", ")),
 		fmt.Sprintf("[%s]", strings.Join(renderedPercentages, ", ")),
 		blueColor)
+	return renderChart(width, height, patchedOptions)
+}
 
+// renderChart renders echarts options to an inline image.
+func renderChart(width, height int, options string) (string, error) {
 	echarts := echartsgoja.New(echartsgoja.WithWidthHeight(width, height))
-	svg, err := echarts.RenderOptions(context.Background(), patchedOptions)
+	svg, err := echarts.RenderOptions(context.Background(), options)
 	if err != nil {
-		log.Fatal(err)
+		return "", fmt.Errorf("render chart: %w", err)
 	}
-
 	dataURI, err := sVGStringToPNGDataURI(svg, jpegQuality, width, height)
 	if err != nil {
-		log.Fatalf("conversion failed: %v", err)
+		return "", fmt.Errorf("conversion failed: %w", err)
 	}
-	return dataURI
+	return dataURI, nil
 }
 
 const simpleOptsBar = `{
@@ -679,19 +906,10 @@ const simpleOptsBar = `{
 }
 `
 
-func generateGaugeDataURI(width, height int, label, color string, value, total int) string { //nolint:unparam
+func generateGaugeDataURI(width, height int, label, color string, value, total int) (string, error) { //nolint:unparam
 	percent := float64(value) / float64(total) * 100.0 //nolint:mnd
 	patchedOptions := fmt.Sprintf(simpleOptsGauge, color, color, int(percent), strings.ToUpper(label), value, total)
-	echarts := echartsgoja.New(echartsgoja.WithWidthHeight(width, height))
-	svg, err := echarts.RenderOptions(context.Background(), patchedOptions)
-	if err != nil {
-		log.Fatal(err)
-	}
-	dataURI, err := sVGStringToPNGDataURI(svg, jpegQuality, width, height)
-	if err != nil {
-		log.Fatalf("conversion failed: %v", err)
-	}
-	return dataURI
+	return renderChart(width, height, patchedOptions)
 }
 
 const simpleOptsGauge = `{
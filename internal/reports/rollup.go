@@ -0,0 +1,254 @@
+package reports
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+	"github.com/edcdavid/jira-helper/internal/jirahelper"
+)
+
+const (
+	epicLinkFieldName = "Epic Link"
+	// rollupBatchSize is the number of epics whose children are fetched with one query.
+	rollupBatchSize = 50
+
+	progressBarCells = 10
+
+	completionWidth     = 600
+	completionRowHeight = 22
+	completionMargin    = 10
+)
+
+// storyPointsFieldNames are the names of the story points field on Jira Server and Cloud.
+var storyPointsFieldNames = []string{"Story Points", "Story point estimate"}
+
+// EpicRollup counts the child issues of an epic by status category, and their story points.
+type EpicRollup struct {
+	Total      int
+	Done       int
+	InProgress int
+	ToDo       int
+	Points     float64
+	PointsDone float64
+}
+
+// Percent is the completion of the epic: the share of its children done, the measure of the
+// completion chart bars. The story points are shown alongside, as not every child is estimated.
+func (r *EpicRollup) Percent() int {
+	if r.Total == 0 {
+		return 0
+	}
+	return r.Done * 100 / r.Total //nolint:mnd
+}
+
+// markdown renders the rollup as a report bullet with a text progress bar.
+func (r *EpicRollup) markdown() string {
+	if r.Total == 0 {
+		return "    - Progress: no child issues\n"
+	}
+	filled := r.Percent() * progressBarCells / 100 //nolint:mnd
+	bar := strings.Repeat("▓", filled) + strings.Repeat("░", progressBarCells-filled)
+	points := ""
+	if r.Points > 0 {
+		points = fmt.Sprintf(", %s/%s points", formatPoints(r.PointsDone), formatPoints(r.Points))
+	}
+	return fmt.Sprintf("    - Progress: `%s` %d%% (%d/%d issues done, %d in progress%s)\n",
+		bar, r.Percent(), r.Done, r.Total, r.InProgress, points)
+}
+
+func formatPoints(points float64) string {
+	return strconv.FormatFloat(points, 'f', -1, 64)
+}
+
+// FetchEpicRollups fetches the children of the epics, linked through the Epic Link or the
+// parent field, and counts them per epic.
+func FetchEpicRollups(ctx context.Context, client *jira.Client, epics []jira.Issue) (map[string]EpicRollup, error) {
+	fieldIDs, err := jirahelper.FieldIDsByName(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	epicLinkID := fieldIDs[epicLinkFieldName]
+	var storyPointsIDs []string
+	for _, name := range storyPointsFieldNames {
+		if id, ok := fieldIDs[name]; ok {
+			storyPointsIDs = append(storyPointsIDs, id)
+		}
+	}
+
+	rollups := map[string]EpicRollup{}
+	for start := 0; start < len(epics); start += rollupBatchSize {
+		batch := epics[start:min(start+rollupBatchSize, len(epics))]
+		keys := make([]string, len(batch))
+		for i := range batch {
+			keys[i] = batch[i].Key
+			rollups[batch[i].Key] = EpicRollup{}
+		}
+		children, err := jirahelper.FetchAllIssues(ctx, client, epicChildrenQuery(keys, epicLinkID), maxIssuesRetrieved)
+		if err != nil {
+			return nil, err
+		}
+		for i := range children {
+			epic := childEpicKey(&children[i], epicLinkID)
+			rollup, ok := rollups[epic]
+			if !ok {
+				continue
+			}
+			rollup.add(&children[i], storyPointsIDs)
+			rollups[epic] = rollup
+		}
+	}
+	log.Printf("Fetched the children of %d epics", len(epics))
+	return rollups, nil
+}
+
+// epicChildrenQuery returns the query of the children of the epics, linked through the Epic
+// Link field, when the server has one, or the parent field.
+func epicChildrenQuery(keys []string, epicLinkID string) string {
+	keyList := strings.Join(keys, ", ")
+	jql := fmt.Sprintf("parent in (%s)", keyList)
+	if epicLinkID != "" {
+		jql = fmt.Sprintf("%q in (%s) or %s", epicLinkFieldName, keyList, jql)
+	}
+	return jql
+}
+
+// childEpicKey returns the epic of a child issue, from the Epic Link field or the parent.
+func childEpicKey(child *jira.Issue, epicLinkID string) string {
+	if epicLinkID != "" {
+		if key, ok := child.Fields.Unknowns[epicLinkID].(string); ok && key != "" {
+			return key
+		}
+	}
+	if child.Fields.Parent != nil {
+		return child.Fields.Parent.Key
+	}
+	return ""
+}
+
+func (r *EpicRollup) add(child *jira.Issue, storyPointsIDs []string) {
+	r.Total++
+	category := ""
+	if child.Fields.Status != nil {
+		category = child.Fields.Status.StatusCategory.Key
+	}
+	done := category == jira.StatusCategoryComplete
+	switch {
+	case done:
+		r.Done++
+	case category == jira.StatusCategoryInProgress:
+		r.InProgress++
+	default:
+		r.ToDo++
+	}
+	for _, id := range storyPointsIDs {
+		points, ok := child.Fields.Unknowns[id].(float64)
+		if !ok {
+			continue
+		}
+		r.Points += points
+		if done {
+			r.PointsDone += points
+		}
+		break
+	}
+}
+
+// generateCompletionDataURI renders the release completion chart: one stacked bar per epic with
+// the share of its children done (green), in progress (blue) and to do (grey), below the total
+// of the release. The percentage of the labels is the share of children done, as the bars.
+func generateCompletionDataURI(epics []jira.Issue, rollups map[string]EpicRollup) (string, error) {
+	type row struct {
+		label  string
+		rollup EpicRollup
+	}
+	var rows []row
+	var total EpicRollup
+	for i := range epics {
+		rollup, ok := rollups[epics[i].Key]
+		if !ok || rollup.Total == 0 {
+			continue
+		}
+		rows = append(rows, row{epics[i].Key + " " + getCustomField(colorField, &epics[i]), rollup})
+		total.Total += rollup.Total
+		total.Done += rollup.Done
+		total.InProgress += rollup.InProgress
+		total.ToDo += rollup.ToDo
+		total.Points += rollup.Points
+		total.PointsDone += rollup.PointsDone
+	}
+	if len(rows) == 0 {
+		return "", nil
+	}
+	// echarts draws the first category at the bottom: least complete epics end up on top.
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].rollup.Percent() > rows[j].rollup.Percent()
+	})
+	rows = append(rows, row{"RELEASE", total})
+
+	var labels, done, inProgress, toDo []string
+	for _, r := range rows {
+		labels = append(labels, strconv.Quote(fmt.Sprintf("%s %d%%", r.label, r.rollup.Percent())))
+		done = append(done, percentString(r.rollup.Done, r.rollup.Total))
+		inProgress = append(inProgress, percentString(r.rollup.InProgress, r.rollup.Total))
+		toDo = append(toDo, percentString(r.rollup.ToDo, r.rollup.Total))
+	}
+	patchedOptions := fmt.Sprintf(simpleOptsCompletion,
+		"["+strings.Join(labels, ", ")+"]",
+		"["+strings.Join(done, ", ")+"]",
+		"["+strings.Join(inProgress, ", ")+"]",
+		"["+strings.Join(toDo, ", ")+"]")
+
+	return renderChart(completionWidth, completionRowHeight*len(rows)+completionMargin, patchedOptions)
+}
+
+func percentString(value, total int) string {
+	return strconv.FormatFloat(float64(value)/float64(total)*100.0, 'f', 1, 64) //nolint:mnd
+}
+
+const simpleOptsCompletion = `{
+  "backgroundColor": "white",
+  "grid": { "left": 160, "top": 5, "bottom": 5, "right": 20 },
+  "xAxis": {
+    "type": "value",
+    "max": 100,
+    "axisLine": { "show": false },
+    "axisTick": { "show": false },
+    "splitLine": { "show": false },
+    "axisLabel": { "show": false }
+  },
+  "yAxis": {
+    "type": "category",
+    "data": %s,
+    "axisLabel": { "fontSize": 9 },
+    "axisLine": { "show": false },
+    "axisTick": { "show": false }
+  },
+  "series": [
+    {
+      "name": "Done",
+      "type": "bar",
+      "stack": "completion",
+      "data": %s,
+      "itemStyle": { "color": "#3CB371" }
+    },
+    {
+      "name": "In progress",
+      "type": "bar",
+      "stack": "completion",
+      "data": %s,
+      "itemStyle": { "color": "#015CE6" }
+    },
+    {
+      "name": "To do",
+      "type": "bar",
+      "stack": "completion",
+      "data": %s,
+      "itemStyle": { "color": "#D3D3D3" }
+    }
+  ]
+}`
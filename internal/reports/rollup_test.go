@@ -0,0 +1,93 @@
+package reports
+
+import (
+	"strings"
+	"testing"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+)
+
+const (
+	testEpicLinkID    = "customfield_1"
+	testStoryPointsID = "customfield_2"
+)
+
+func rollupChild(category string, unknowns map[string]any) *jira.Issue {
+	return &jira.Issue{Fields: &jira.IssueFields{
+		Status:   &jira.Status{StatusCategory: jira.StatusCategory{Key: category}},
+		Unknowns: unknowns,
+	}}
+}
+
+func TestEpicRollupAdd(t *testing.T) {
+	var rollup EpicRollup
+	for _, child := range []*jira.Issue{
+		rollupChild(jira.StatusCategoryComplete, map[string]any{testStoryPointsID: 3.0}),
+		rollupChild(jira.StatusCategoryComplete, nil),
+		rollupChild(jira.StatusCategoryInProgress, map[string]any{testStoryPointsID: 5.0}),
+		rollupChild(jira.StatusCategoryToDo, map[string]any{testStoryPointsID: "not a number"}),
+		{Fields: &jira.IssueFields{}},
+	} {
+		rollup.add(child, []string{"customfield_0", testStoryPointsID})
+	}
+	want := EpicRollup{Total: 5, Done: 2, InProgress: 1, ToDo: 2, Points: 8, PointsDone: 3}
+	if rollup != want {
+		t.Errorf("rollup = %+v, want %+v", rollup, want)
+	}
+}
+
+func TestEpicRollupPercent(t *testing.T) {
+	tests := []struct {
+		name   string
+		rollup EpicRollup
+		want   int
+	}{
+		{name: "no children", rollup: EpicRollup{}, want: 0},
+		{name: "not estimated", rollup: EpicRollup{Total: 4, Done: 1}, want: 25},
+		// The story points are not the measure of the completion, as the chart bars.
+		{name: "estimated", rollup: EpicRollup{Total: 4, Done: 1, Points: 10, PointsDone: 8}, want: 25},
+		{name: "done", rollup: EpicRollup{Total: 3, Done: 3}, want: 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rollup.Percent(); got != tt.want {
+				t.Errorf("Percent() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	markdown := (&EpicRollup{Total: 4, Done: 1, InProgress: 2, ToDo: 1, Points: 10, PointsDone: 8}).markdown()
+	if want := "`▓▓░░░░░░░░` 25% (1/4 issues done, 2 in progress, 8/10 points)"; !strings.Contains(markdown, want) {
+		t.Errorf("markdown = %q, want %q", markdown, want)
+	}
+}
+
+func TestChildEpicKey(t *testing.T) {
+	withParent := func(issue *jira.Issue) *jira.Issue {
+		issue.Fields.Parent = &jira.Parent{Key: "CNF-2"}
+		return issue
+	}
+	tests := []struct {
+		name       string
+		child      *jira.Issue
+		epicLinkID string
+		want       string
+	}{
+		{name: "epic link", child: rollupChild("", map[string]any{testEpicLinkID: "CNF-1"}),
+			epicLinkID: testEpicLinkID, want: "CNF-1"},
+		{name: "epic link before parent", child: withParent(rollupChild("", map[string]any{testEpicLinkID: "CNF-1"})),
+			epicLinkID: testEpicLinkID, want: "CNF-1"},
+		{name: "empty epic link", child: withParent(rollupChild("", map[string]any{testEpicLinkID: ""})),
+			epicLinkID: testEpicLinkID, want: "CNF-2"},
+		{name: "no epic link field", child: withParent(rollupChild("", map[string]any{testEpicLinkID: "CNF-1"})),
+			want: "CNF-2"},
+		{name: "no epic", child: rollupChild("", nil), epicLinkID: testEpicLinkID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := childEpicKey(tt.child, tt.epicLinkID); got != tt.want {
+				t.Errorf("childEpicKey = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,321 @@
+package reports
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+	"github.com/edcdavid/jira-helper/internal/jirahelper"
+)
+
+const (
+	scopeAdded   = "Added"
+	scopeRemoved = "Removed"
+	scopeMoved   = "Moved to"
+)
+
+// ScopeOptions configures GetScopeReport.
+type ScopeOptions struct {
+	JiraURL             string
+	PersonalAccessToken string
+	// Projects are the projects whose issues are followed.
+	Projects  []string
+	IssueType string
+	// FixVersion is the version whose scope changes are listed, for example openshift-4.20.
+	FixVersion string
+	// Since is the date from which the changes are listed, usually the feature freeze.
+	Since string
+}
+
+// scopeEvent is the fix version of an issue set to, or removed from, the followed version.
+type scopeEvent struct {
+	at     time.Time
+	in     bool
+	author string
+	// movedTo is the version set instead of the followed one when it is removed.
+	movedTo string
+}
+
+// scopeChange is a line of the scope change table.
+type scopeChange struct {
+	At     time.Time
+	Change string
+	Later  bool
+	Key    string
+	Title  string
+	Author string
+}
+
+// scopeIssue is an issue that is or was in the followed version.
+type scopeIssue struct {
+	issue   *jira.Issue
+	created time.Time
+	current bool
+	events  []scopeEvent
+}
+
+// GetScopeReport lists the issues of the projects added to or removed from the fix version
+// since a date, with who changed them and when, and draws the scope and the work done in it
+// each week.
+func GetScopeReport(opts ScopeOptions) {
+	initLog()
+	since, err := time.Parse(time.DateOnly, opts.Since)
+	if err != nil {
+		log.Fatalf("Invalid since date %q, err:%v", opts.Since, err)
+	}
+	if len(opts.Projects) == 0 {
+		log.Fatal("at least one project is required")
+	}
+	client, err := jirahelper.NewClient(opts.JiraURL, opts.PersonalAccessToken)
+	if err != nil {
+		log.Fatal(err)
+	}
+	projects := make([]string, len(opts.Projects))
+	for i, project := range opts.Projects {
+		projects[i] = strconv.Quote(project)
+	}
+	jql := fmt.Sprintf("project in (%s) and issuetype = %q and (fixVersion = %q or fixVersion was %q)",
+		strings.Join(projects, ", "), opts.IssueType, opts.FixVersion, opts.FixVersion)
+	issues, err := jirahelper.FetchAllIssuesExpanded(context.TODO(), client, jql, maxIssuesRetrieved, "changelog")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	scopeIssues := make([]scopeIssue, len(issues))
+	for i := range issues {
+		scopeIssues[i] = newScopeIssue(&issues[i], opts.FixVersion)
+	}
+	if err := writeScopeReport(os.Stdout, scopeIssues, opts.JiraURL, opts.FixVersion, since, time.Now()); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// isFixVersionField tells whether a changelog item changes the fix versions.
+func isFixVersionField(field string) bool {
+	switch strings.ToLower(strings.ReplaceAll(field, " ", "")) {
+	case "fixversion", "fixversions":
+		return true
+	default:
+		return false
+	}
+}
+
+// newScopeIssue reads the changes of the followed version from the changelog of the issue.
+func newScopeIssue(issue *jira.Issue, version string) scopeIssue {
+	s := scopeIssue{issue: issue, created: time.Time(issue.Fields.Created)}
+	for _, fixVersion := range issue.Fields.FixVersions {
+		if fixVersion.Name == version {
+			s.current = true
+		}
+	}
+	if issue.Changelog == nil {
+		return s
+	}
+	for _, history := range issue.Changelog.Histories {
+		at, err := time.Parse(jiraTimeLayout, history.Created)
+		if err != nil {
+			continue
+		}
+		var added []string
+		var event *scopeEvent
+		for _, item := range history.Items {
+			if !isFixVersionField(item.Field) {
+				continue
+			}
+			switch {
+			case item.ToString == version && item.FromString != version:
+				event = &scopeEvent{at: at, in: true, author: history.Author.DisplayName}
+			case item.FromString == version && item.ToString != version:
+				event = &scopeEvent{at: at, author: history.Author.DisplayName, movedTo: item.ToString}
+			case item.ToString != "":
+				added = append(added, item.ToString)
+			}
+		}
+		if event == nil {
+			continue
+		}
+		if !event.in && event.movedTo == "" && len(added) > 0 {
+			event.movedTo = added[0]
+		}
+		s.events = append(s.events, *event)
+	}
+	sort.SliceStable(s.events, func(i, j int) bool { return s.events[i].at.Before(s.events[j].at) })
+	return s
+}
+
+// inScope tells whether the issue was in the followed version at the given time: the state
+// before the next change, or its current state when it has not changed since.
+func (s *scopeIssue) inScope(at time.Time) bool {
+	if !s.created.IsZero() && at.Before(s.created) {
+		return false
+	}
+	for _, event := range s.events {
+		if event.at.After(at) {
+			return !event.in
+		}
+	}
+	return s.current
+}
+
+// doneAt tells whether the issue was resolved at the given time.
+func (s *scopeIssue) doneAt(at time.Time) bool {
+	done := false
+	for _, change := range resolutionChanges(s.issue) {
+		if change.at.After(at) {
+			break
+		}
+		done = change.resolved
+	}
+	return done
+}
+
+// changes returns the changes of the followed version since the date. An issue created in the
+// version after the date is added by its reporter.
+func (s *scopeIssue) changes(version string, since time.Time) []scopeChange {
+	var changes []scopeChange
+	title := s.issue.Key + ": " + s.issue.Fields.Summary
+	if !s.created.Before(since) && s.inScope(s.created) {
+		author := ""
+		if s.issue.Fields.Reporter != nil {
+			author = s.issue.Fields.Reporter.DisplayName
+		}
+		changes = append(changes, scopeChange{At: s.created, Change: scopeAdded + " (created)", Key: s.issue.Key,
+			Title: title, Author: author})
+	}
+	for _, event := range s.events {
+		if event.at.Before(since) {
+			continue
+		}
+		change := scopeChange{At: event.at, Change: scopeAdded, Key: s.issue.Key, Title: title, Author: event.author}
+		switch {
+		case event.in:
+		case event.movedTo != "":
+			change.Change = scopeMoved + " " + event.movedTo
+			change.Later = compareVersions(event.movedTo, version) > 0
+		default:
+			change.Change = scopeRemoved
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// compareVersions compares the versions number by number, as 4.9 before 4.10, and the other
+// parts of the version as text.
+func compareVersions(a, b string) int {
+	pa, pb := versionParts(a), versionParts(b)
+	for i := 0; i < len(pa) && i < len(pb); i++ {
+		na, errA := strconv.Atoi(pa[i])
+		nb, errB := strconv.Atoi(pb[i])
+		switch {
+		case errA == nil && errB == nil && na != nb:
+			return na - nb
+		case (errA != nil || errB != nil) && pa[i] != pb[i]:
+			return strings.Compare(pa[i], pb[i])
+		}
+	}
+	return len(pa) - len(pb)
+}
+
+// versionParts splits a version into its runs of digits and of other characters.
+func versionParts(version string) []string {
+	var parts []string
+	current := ""
+	for _, r := range version {
+		if current != "" && unicode.IsDigit(r) != unicode.IsDigit(rune(current[len(current)-1])) {
+			parts = append(parts, current)
+			current = ""
+		}
+		current += string(r)
+	}
+	if current != "" {
+		parts = append(parts, current)
+	}
+	return parts
+}
+
+func writeScopeReport(w io.Writer, issues []scopeIssue, jiraURL, version string, since, now time.Time) error {
+	var changes []scopeChange
+	for i := range issues {
+		changes = append(changes, issues[i].changes(version, since)...)
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].At.Before(changes[j].At) })
+
+	added, removed, later := 0, 0, 0
+	for _, change := range changes {
+		switch {
+		case strings.HasPrefix(change.Change, scopeAdded):
+			added++
+		case change.Later:
+			later++
+		default:
+			removed++
+		}
+	}
+	scopeAt := func(at time.Time) (scope, done int) {
+		for i := range issues {
+			if issues[i].inScope(at) {
+				scope++
+				if issues[i].doneAt(at) {
+					done++
+				}
+			}
+		}
+		return scope, done
+	}
+	before, _ := scopeAt(since)
+	scope, done := scopeAt(now)
+
+	fmt.Fprintf(w, "<span style=\"background-color:black; color:white\">SCOPE %s</span>\n\n", version)
+	fmt.Fprintf(w, "- %d issues on %s, %d now, %d done\n", before, since.Format(time.DateOnly), scope, done)
+	fmt.Fprintf(w, "- %d added, %d removed, %d moved to a later release\n", added, removed, later)
+	if len(changes) > 0 {
+		fmt.Fprintln(w, "\n| Date | Change | Issue | By |\n| --- | --- | --- | --- |")
+		for _, change := range changes {
+			fmt.Fprintf(w, "| %s | %s | [%s](%s) | %s |\n", change.At.Format(time.DateOnly), markdownCell(change.Change),
+				markdownCell(change.Title), issueURL(jiraURL, change.Key), markdownCell(change.Author))
+		}
+	}
+
+	var weeks []time.Time
+	for week := since; week.Before(now); week = week.AddDate(0, 0, daysPerWeek) {
+		weeks = append(weeks, week)
+	}
+	weeks = append(weeks, now)
+	labels := make([]string, len(weeks))
+	scopes := make([]int, len(weeks))
+	dones := make([]int, len(weeks))
+	for i, week := range weeks {
+		labels[i] = strconv.Quote(week.Format(time.DateOnly))
+		scopes[i], dones[i] = scopeAt(week)
+	}
+	chart, err := renderTrendChart(fmt.Sprintf(simpleOptsScope, "["+strings.Join(labels, ", ")+"]",
+		intList(scopes), intList(dones)))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "\n_Issues in %s (blue) and done (green) each week_", version)
+	fmt.Fprintln(w, chart)
+	return nil
+}
+
+const simpleOptsScope = `{
+  "backgroundColor": "white",
+  "grid": { "left": 40, "top": 10, "bottom": 25, "right": 20 },
+  "xAxis": { "type": "category", "data": %s, "axisLabel": { "fontSize": 8 } },
+  "yAxis": { "type": "value", "minInterval": 1, "axisLabel": { "fontSize": 8 } },
+  "series": [
+    { "name": "Scope", "type": "line", "step": "end", "showSymbol": false, "data": %s,
+      "areaStyle": { "color": "#B3CDF5" }, "itemStyle": { "color": "#015CE6" } },
+    { "name": "Done", "type": "line", "step": "end", "showSymbol": false, "data": %s,
+      "areaStyle": { "color": "#B3E6B3" }, "itemStyle": { "color": "#3CB371" } }
+  ]
+}`
@@ -0,0 +1,161 @@
+package reports
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "4.9", b: "4.10", want: -1},
+		{a: "4.10", b: "4.9", want: 1},
+		{a: "openshift-4.9", b: "openshift-4.10", want: -1},
+		{a: "openshift-4.20", b: "openshift-4.20", want: 0},
+		{a: "openshift-4.21", b: "openshift-4.20", want: 1},
+		{a: "4.20.z", b: "4.20", want: 1},
+		{a: "4.20.z", b: "4.21", want: -1},
+		{a: "4.20-rc1", b: "4.20-rc2", want: -1},
+		{a: "4.20a", b: "4.20b", want: -1},
+		{a: "openshift-4.20", b: "rhel-9", want: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			got := compareVersions(tt.a, tt.b)
+			if sign(got) != tt.want {
+				t.Errorf("compareVersions(%q, %q) = %d, want the sign of %d", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func scopeDate(day string) time.Time {
+	at, _ := time.Parse(time.DateOnly, day)
+	return at
+}
+
+// fixVersionHistory is a change of the fix versions on the day given, one item per from and to
+// pair, as Jira logs a version replaced by another: a removal item and an addition item.
+func fixVersionHistory(day, author string, items ...[2]string) jira.ChangelogHistory {
+	history := jira.ChangelogHistory{Created: scopeDate(day).Format(jiraTimeLayout),
+		Author: jira.User{DisplayName: author}}
+	for _, item := range items {
+		history.Items = append(history.Items, jira.ChangelogItems{Field: "Fix Version", FieldType: "jira",
+			FromString: item[0], ToString: item[1]})
+	}
+	return history
+}
+
+func scopeFixture(key, created string, fixVersions []string, histories ...jira.ChangelogHistory) *jira.Issue {
+	issue := &jira.Issue{Key: key, Fields: &jira.IssueFields{Summary: "summary of " + key,
+		Created: jira.Time(scopeDate(created)), Reporter: &jira.User{DisplayName: "Reporter"}}}
+	for _, name := range fixVersions {
+		issue.Fields.FixVersions = append(issue.Fields.FixVersions, &jira.FixVersion{Name: name})
+	}
+	if len(histories) > 0 {
+		issue.Changelog = &jira.Changelog{Histories: histories}
+	}
+	return issue
+}
+
+func TestScopeIssue(t *testing.T) {
+	const version = "openshift-4.20"
+	since := scopeDate("2026-08-01")
+	tests := []struct {
+		name    string
+		issue   *jira.Issue
+		inScope map[string]bool
+		changes []scopeChange
+	}{
+		{
+			name: "moved to a later version",
+			issue: scopeFixture("CNF-1", "2026-07-01", []string{"openshift-4.21"},
+				fixVersionHistory("2026-07-10", "Alice", [2]string{"", version}),
+				fixVersionHistory("2026-09-01", "Bob", [2]string{version, ""}, [2]string{"", "openshift-4.21"})),
+			inScope: map[string]bool{"2026-06-30": false, "2026-07-05": false, "2026-08-01": true, "2026-09-02": false},
+			changes: []scopeChange{{At: scopeDate("2026-09-01"), Change: "Moved to openshift-4.21", Later: true,
+				Author: "Bob"}},
+		},
+		{
+			name: "moved to an earlier version, addition logged first",
+			issue: scopeFixture("CNF-2", "2026-07-01", []string{"openshift-4.19"},
+				fixVersionHistory("2026-09-01", "Bob", [2]string{"", "openshift-4.19"}, [2]string{version, ""})),
+			inScope: map[string]bool{"2026-08-01": true, "2026-09-02": false},
+			changes: []scopeChange{{At: scopeDate("2026-09-01"), Change: "Moved to openshift-4.19", Author: "Bob"}},
+		},
+		{
+			name: "removed",
+			issue: scopeFixture("CNF-3", "2026-07-01", nil,
+				fixVersionHistory("2026-08-15", "Carol", [2]string{version, ""})),
+			inScope: map[string]bool{"2026-08-14": true, "2026-08-16": false},
+			changes: []scopeChange{{At: scopeDate("2026-08-15"), Change: scopeRemoved, Author: "Carol"}},
+		},
+		{
+			name:    "created in the version",
+			issue:   scopeFixture("CNF-4", "2026-08-20", []string{version}),
+			inScope: map[string]bool{"2026-08-19": false, "2026-08-21": true},
+			changes: []scopeChange{{At: scopeDate("2026-08-20"), Change: scopeAdded + " (created)", Author: "Reporter"}},
+		},
+		{
+			name: "added before the date",
+			issue: scopeFixture("CNF-5", "2026-07-01", []string{version},
+				fixVersionHistory("2026-07-10", "Alice", [2]string{"", version})),
+			inScope: map[string]bool{"2026-07-09": false, "2026-07-11": true},
+		},
+	}
+	var issues []scopeIssue
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newScopeIssue(tt.issue, version)
+			issues = append(issues, s)
+			for day, want := range tt.inScope {
+				if got := s.inScope(scopeDate(day)); got != want {
+					t.Errorf("inScope(%s) = %v, want %v", day, got, want)
+				}
+			}
+			changes := s.changes(version, since)
+			if len(changes) != len(tt.changes) {
+				t.Fatalf("changes = %+v, want %+v", changes, tt.changes)
+			}
+			for i, want := range tt.changes {
+				want.Key = tt.issue.Key
+				want.Title = tt.issue.Key + ": summary of " + tt.issue.Key
+				got := changes[i]
+				if !got.At.Equal(want.At) {
+					t.Errorf("change %d at %s, want %s", i, got.At, want.At)
+				}
+				got.At, want.At = time.Time{}, time.Time{}
+				if got != want {
+					t.Errorf("change %d = %+v, want %+v", i, got, want)
+				}
+			}
+		})
+	}
+
+	var sb strings.Builder
+	if err := writeScopeReport(&sb, issues, "https://issues.example.com", version, since,
+		scopeDate("2026-10-01")); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"- 4 issues on 2026-08-01, 2 now, 0 done", "- 1 added, 2 removed, 1 moved to a later release"} {
+		if !strings.Contains(sb.String(), want) {
+			t.Errorf("report does not contain %q:\n%s", want, sb.String())
+		}
+	}
+}
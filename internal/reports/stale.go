@@ -0,0 +1,56 @@
+package reports
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+	"github.com/edcdavid/jira-helper/internal/statussummary"
+	"github.com/edcdavid/jira-helper/internal/timehelper"
+)
+
+const statusSummaryFieldName = "Status Summary"
+
+// StatusSummaryDate returns the date of the most recent status entry: the date written in
+// the entry, or the last change of the field in the changelog when no entry is dated.
+func StatusSummaryDate(issue *jira.Issue, now time.Time) (time.Time, bool) {
+	if entry, ok := statussummary.Latest(getCustomField(statusSummaryField, issue), now); ok {
+		return entry.Date, true
+	}
+	if issue.Changelog == nil {
+		return time.Time{}, false
+	}
+
+	var latest time.Time
+	for _, history := range issue.Changelog.Histories {
+		for _, item := range history.Items {
+			if !strings.EqualFold(item.Field, statusSummaryFieldName) {
+				continue
+			}
+			if created, err := time.Parse(jiraTimeLayout, history.Created); err == nil && created.After(latest) {
+				latest = created
+			}
+		}
+	}
+	return latest, !latest.IsZero()
+}
+
+// isStale tells whether an issue moves to the stale section: a red or yellow issue whose
+// latest status entry is older than staleAfter. Zero disables the stale detection.
+func isStale(color string, date time.Time, dated bool, staleAfter time.Duration, now time.Time) bool {
+	return staleAfter > 0 && (color == colorRed || color == colorYellow) && dated && now.Sub(date) > staleAfter
+}
+
+// statusAgeLabel is appended to the issue line in the report. The color is given for the
+// issues listed in the stale section, away from their color section.
+func statusAgeLabel(color string, date time.Time, dated bool, now time.Time) string {
+	prefix := ""
+	if color != "" {
+		prefix = color + ", "
+	}
+	if !dated {
+		return fmt.Sprintf(" _(%sno dated status)_", prefix)
+	}
+	return fmt.Sprintf(" _(%sstatus %d days old)_", prefix, timehelper.Days(now.Sub(date)))
+}
@@ -0,0 +1,41 @@
+package reports
+
+import (
+	"testing"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+	"github.com/edcdavid/jira-helper/internal/timehelper"
+)
+
+func TestBuildReportSummaryStale(t *testing.T) {
+	now := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+	issue := func(key, color, statusSummary string) jira.Issue {
+		unknowns := map[string]any{statusSummaryField: statusSummary}
+		if color != "" {
+			unknowns[colorField] = map[string]any{"value": color}
+		}
+		return jira.Issue{Key: key, Fields: &jira.IssueFields{Unknowns: unknowns}}
+	}
+	issues := []jira.Issue{
+		issue("CNF-1", colorRed, "2026-08-01: blocked on review"),
+		issue("CNF-2", colorYellow, "2026-09-28: waiting for the image"),
+		issue("CNF-3", colorGreen, "2026-08-01: on track"),
+		issue("CNF-4", "", "2026-08-01: no color yet"),
+	}
+
+	summary := BuildReportSummary(issues, &ReportOptions{StaleAfter: 14 * timehelper.Day}, now)
+	if summary.Stale != 1 {
+		t.Errorf("Stale = %d, want 1", summary.Stale)
+	}
+	for _, issue := range summary.Issues {
+		if want := issue.Key == "CNF-1"; issue.Stale != want {
+			t.Errorf("%s: Stale = %v, want %v", issue.Key, issue.Stale, want)
+		}
+	}
+
+	summary = BuildReportSummary(issues, &ReportOptions{}, now)
+	if summary.Stale != 0 {
+		t.Errorf("Stale without --stale-after = %d, want 0", summary.Stale)
+	}
+}
@@ -0,0 +1,97 @@
+package reports
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+	"github.com/edcdavid/jira-helper/internal/ollamahelper"
+	"github.com/edcdavid/jira-helper/internal/prompts"
+	"github.com/edcdavid/jira-helper/internal/statussummary"
+)
+
+// summaryIssue is the data made available to the executive summary prompt template.
+type summaryIssue struct {
+	Key          string
+	Title        string
+	Color        string
+	State        string
+	Date         string
+	LatestStatus string
+}
+
+var issueKeyRe = regexp.MustCompile(`\[?\b([A-Z][A-Z0-9]+-\d+)\b\]?`)
+
+func newSummaryIssue(issue *jira.Issue, color, statusSummary string) summaryIssue {
+	item := summaryIssue{
+		Key:          issue.Key,
+		Title:        issue.Fields.Summary,
+		Color:        color,
+		State:        issue.Fields.Status.Name,
+		LatestStatus: strings.TrimSpace(strings.ReplaceAll(statusSummary, "\r", "")),
+	}
+	if entry, ok := statussummary.Latest(statusSummary, time.Now()); ok {
+		item.Date = entry.Date.Format("01/02/2006")
+		item.LatestStatus = entry.Text
+	}
+	if item.LatestStatus == "" {
+		item.LatestStatus = "No status summary."
+	}
+	return item
+}
+
+// aiExecutiveSummary asks the model for a summary of the red and yellow issues. Issue keys
+// cited by the model are turned into links, and keys that were not part of the input are
+// flagged so readers do not trust an invented reference.
+func aiExecutiveSummary(jiraURL string, issues []summaryIssue, ollamaModel, promptOverride string) (string, error) {
+	if len(issues) == 0 {
+		return "No red or yellow issues.\n", nil
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Color == colorRed && issues[j].Color != colorRed
+	})
+
+	prompt, err := prompts.Render(prompts.Summary, promptOverride, struct{ Issues []summaryIssue }{issues})
+	if err != nil {
+		return "", fmt.Errorf("cannot render summary prompt: %w", err)
+	}
+
+	answer, err := ollamahelper.Chat(context.Background(), ollamaModel, "", prompt)
+	if err != nil {
+		return "", fmt.Errorf("summary chat request failed: %w", err)
+	}
+
+	return linkIssueKeys(answer, jiraURL, issues) + "\n", nil
+}
+
+// linkIssueKeys turns the issue keys of the answer into links and flags the keys that were not
+// part of the input. Only the keys of the projects of the input are checked, so that tokens of
+// the same shape such as UTF-8 are left as they are.
+func linkIssueKeys(answer, jiraURL string, issues []summaryIssue) string {
+	known := map[string]bool{}
+	projects := map[string]bool{}
+	for _, issue := range issues {
+		known[issue.Key] = true
+		projects[strings.SplitN(issue.Key, "-", 2)[0]] = true //nolint:mnd
+	}
+	return issueKeyRe.ReplaceAllStringFunc(answer, func(match string) string {
+		key := issueKeyRe.FindStringSubmatch(match)[1]
+		switch {
+		case known[key]:
+			return fmt.Sprintf("[%s](%s)", key, issueURL(jiraURL, key))
+		case projects[strings.SplitN(key, "-", 2)[0]]: //nolint:mnd
+			return match + " (unverified)"
+		default:
+			return match
+		}
+	})
+}
+
+func issueURL(jiraURL, key string) string {
+	return strings.TrimSuffix(jiraURL, "/") + "/browse/" + key
+}
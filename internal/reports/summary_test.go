@@ -0,0 +1,27 @@
+package reports
+
+import "testing"
+
+func TestLinkIssueKeys(t *testing.T) {
+	issues := []summaryIssue{{Key: "CNF-1"}, {Key: "OCPBUGS-22"}}
+	tests := []struct {
+		name   string
+		answer string
+		want   string
+	}{
+		{name: "known key", answer: "CNF-1 is blocked.",
+			want: "[CNF-1](https://issues.example.com/browse/CNF-1) is blocked."},
+		{name: "bracketed known key", answer: "See [OCPBUGS-22].",
+			want: "See [OCPBUGS-22](https://issues.example.com/browse/OCPBUGS-22)."},
+		{name: "unknown key of an input project", answer: "CNF-99 slipped.", want: "CNF-99 (unverified) slipped."},
+		{name: "key shaped token of another project", answer: "Logs are UTF-8 and ISO-8601.",
+			want: "Logs are UTF-8 and ISO-8601."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := linkIssueKeys(tt.answer, "https://issues.example.com/", issues); got != tt.want {
+				t.Errorf("linkIssueKeys(%q) = %q, want %q", tt.answer, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,335 @@
+package reports
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+	"github.com/edcdavid/jira-helper/internal/jirahelper"
+)
+
+const (
+	resolutionField = "resolution"
+	statusField     = "status"
+	daysPerWeek     = 7
+
+	trendWidth  = 600
+	trendHeight = 200
+)
+
+// BugTrend is the weekly flow of the issues in the queue of a bugstatus.yml filter: the issues
+// neither resolved nor in one of the statuses the filter excludes.
+type BugTrend struct {
+	// Weeks are the first days of the weeks.
+	Weeks []time.Time
+	// Created is the issues entering the queue for the first time, usually when created.
+	Created []int
+	// Left is the issues resolved or moved to an excluded status.
+	Left []int
+	// Returned is the issues entering the queue again, reopened or moved back from an excluded
+	// status.
+	Returned []int
+	// Open is the backlog at the end of each week.
+	Open []int
+	// OpenBefore is the backlog at the start of the first week.
+	OpenBefore int
+	// End is the last day counted.
+	End time.Time
+}
+
+// resolutionChange is a resolution set (resolved) or cleared (reopened) on an issue.
+type resolutionChange struct {
+	at       time.Time
+	resolved bool
+}
+
+// queueChange is an issue entering (in) or leaving the queue of a filter.
+type queueChange struct {
+	at time.Time
+	in bool
+}
+
+// FetchBugTrend fetches the issues of the trend query of a filter with their changelog and
+// counts them per week between fromDate and the release date, or today when it is earlier.
+// Issues in one of the excluded statuses are out of the queue.
+func FetchBugTrend(ctx context.Context, client *jira.Client, trendQuery string, excludedStatuses []string,
+	releaseCutoffDate, fromDate string, now time.Time) (*BugTrend, error) {
+	from, err := time.Parse(time.DateOnly, fromDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from date %q: %w", fromDate, err)
+	}
+	to, err := time.Parse(time.DateOnly, releaseCutoffDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid release date %q: %w", releaseCutoffDate, err)
+	}
+	if now.Before(to) {
+		to = now
+	}
+	issues, err := jirahelper.FetchAllIssuesExpanded(ctx, client, trendQuery, maxIssuesRetrieved, "changelog")
+	if err != nil {
+		return nil, err
+	}
+	return buildBugTrend(issues, excludedStatuses, from, to), nil
+}
+
+// buildBugTrend counts the issues entering, leaving and returning to the queue each week from
+// the first day until the last one, and the issues in the queue at the end of each week.
+func buildBugTrend(issues []jira.Issue, excludedStatuses []string, from, to time.Time) *BugTrend {
+	trend := &BugTrend{End: to}
+	for week := from; !week.After(to); week = week.AddDate(0, 0, daysPerWeek) {
+		trend.Weeks = append(trend.Weeks, week)
+	}
+	weeks := len(trend.Weeks)
+	trend.Created = make([]int, weeks)
+	trend.Left = make([]int, weeks)
+	trend.Returned = make([]int, weeks)
+	delta := make([]int, weeks)
+	weekOf := func(at time.Time) int {
+		if at.Before(from) {
+			return -1
+		}
+		return int(at.Sub(from).Hours() / hoursPerDay / daysPerWeek)
+	}
+
+	for i := range issues {
+		entered := false
+		for _, change := range queueChanges(&issues[i], excludedStatuses) {
+			step := -1
+			if change.in {
+				step = 1
+			}
+			week := weekOf(change.at)
+			switch {
+			case week < 0:
+				trend.OpenBefore += step
+			case week >= weeks:
+			case !change.in:
+				trend.Left[week]++
+			case !entered:
+				trend.Created[week]++
+			default:
+				trend.Returned[week]++
+			}
+			if week >= 0 && week < weeks {
+				delta[week] += step
+			}
+			entered = entered || change.in
+		}
+	}
+
+	open := trend.OpenBefore
+	trend.Open = make([]int, weeks)
+	for week := range trend.Weeks {
+		open += delta[week]
+		trend.Open[week] = open
+	}
+	return trend
+}
+
+// queueChanges returns when the issue entered and left the queue, from the status and
+// resolution changes of its changelog: it is in the queue while it is not resolved and its
+// status is not one of the excluded ones. The status it was created in is the one its first
+// status change moved it from.
+func queueChanges(issue *jira.Issue, excludedStatuses []string) []queueChange {
+	created := time.Time(issue.Fields.Created)
+	if created.IsZero() {
+		return nil
+	}
+	type fieldChange struct {
+		at    time.Time
+		field string
+		from  string
+		to    string
+	}
+	var changes []fieldChange
+	if issue.Changelog != nil {
+		for _, history := range issue.Changelog.Histories {
+			at, err := time.Parse(jiraTimeLayout, history.Created)
+			if err != nil {
+				continue
+			}
+			for _, item := range history.Items {
+				field := strings.ToLower(item.Field)
+				if field == statusField || field == resolutionField {
+					changes = append(changes, fieldChange{at: at, field: field, from: item.FromString, to: item.ToString})
+				}
+			}
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].at.Before(changes[j].at) })
+
+	status := ""
+	if issue.Fields.Status != nil {
+		status = issue.Fields.Status.Name
+	}
+	hasResolution := false
+	for _, change := range changes {
+		hasResolution = hasResolution || change.field == resolutionField
+	}
+	for _, change := range changes {
+		if change.field == statusField {
+			status = change.from
+			break
+		}
+	}
+	if !hasResolution && !time.Time(issue.Fields.Resolutiondate).IsZero() {
+		changes = append(changes, fieldChange{at: time.Time(issue.Fields.Resolutiondate), field: resolutionField,
+			to: "resolved"})
+	}
+
+	resolved := false
+	inQueue := func() bool {
+		return !resolved && !slices.ContainsFunc(excludedStatuses, func(excluded string) bool {
+			return strings.EqualFold(excluded, status)
+		})
+	}
+	var result []queueChange
+	in := inQueue()
+	if in {
+		result = append(result, queueChange{at: created, in: true})
+	}
+	for _, change := range changes {
+		if change.field == statusField {
+			status = change.to
+		} else {
+			resolved = change.to != ""
+		}
+		if inQueue() != in {
+			in = !in
+			result = append(result, queueChange{at: change.at, in: in})
+		}
+	}
+	return result
+}
+
+// resolutionChanges returns when the issue was resolved and reopened, from the resolution
+// changes of its changelog, or from its resolution date when the changelog has none.
+func resolutionChanges(issue *jira.Issue) []resolutionChange {
+	var changes []resolutionChange
+	if issue.Changelog != nil {
+		for _, history := range issue.Changelog.Histories {
+			at, err := time.Parse(jiraTimeLayout, history.Created)
+			if err != nil {
+				continue
+			}
+			for _, item := range history.Items {
+				if strings.EqualFold(item.Field, resolutionField) {
+					changes = append(changes, resolutionChange{at: at, resolved: item.ToString != ""})
+				}
+			}
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].at.Before(changes[j].at) })
+	// Changes repeating the state, as a resolution replaced by another one, are not counted.
+	var result []resolutionChange
+	resolved := false
+	for _, change := range changes {
+		if change.resolved != resolved {
+			result = append(result, change)
+			resolved = change.resolved
+		}
+	}
+	if len(changes) == 0 && !time.Time(issue.Fields.Resolutiondate).IsZero() {
+		result = append(result, resolutionChange{at: time.Time(issue.Fields.Resolutiondate), resolved: true})
+	}
+	return result
+}
+
+// markdown describes the trend in one line and draws its charts.
+func (t *BugTrend) markdown() (string, error) {
+	if len(t.Weeks) == 0 {
+		return "", nil
+	}
+	created, left, returned := sum(t.Created), sum(t.Left), sum(t.Returned)
+	direction := "stable"
+	switch last := t.Open[len(t.Open)-1]; {
+	case last < t.OpenBefore:
+		direction = "shrinking"
+	case last > t.OpenBefore:
+		direction = "growing"
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "  - Backlog %s: %d open on %s, %d on %s (%d created, %d left, %d returned)\n",
+		direction, t.OpenBefore, t.Weeks[0].Format(time.DateOnly), t.Open[len(t.Open)-1],
+		t.End.Format(time.DateOnly), created, left, returned)
+	flow, err := t.flowDataURI()
+	if err != nil {
+		return "", err
+	}
+	backlog, err := t.backlogDataURI()
+	if err != nil {
+		return "", err
+	}
+	sb.WriteString("    - _Issues created (red), leaving (green) and returning to (orange) the queue per week_")
+	sb.WriteString(flow)
+	sb.WriteString("\n    - _Issues in the queue at the end of each week_")
+	sb.WriteString(backlog)
+	return sb.String(), nil
+}
+
+func sum(values []int) int {
+	total := 0
+	for _, value := range values {
+		total += value
+	}
+	return total
+}
+
+// flowDataURI renders the issues created, leaving and returning to the queue per week.
+func (t *BugTrend) flowDataURI() (string, error) {
+	return renderTrendChart(fmt.Sprintf(simpleOptsTrendFlow, t.weekLabels(), intList(t.Created),
+		intList(t.Left), intList(t.Returned)))
+}
+
+// backlogDataURI renders the issues open at the end of each week.
+func (t *BugTrend) backlogDataURI() (string, error) {
+	return renderTrendChart(fmt.Sprintf(simpleOptsTrendBacklog, t.weekLabels(), intList(t.Open)))
+}
+
+func (t *BugTrend) weekLabels() string {
+	labels := make([]string, len(t.Weeks))
+	for i, week := range t.Weeks {
+		labels[i] = strconv.Quote(week.Format(time.DateOnly))
+	}
+	return "[" + strings.Join(labels, ", ") + "]"
+}
+
+func intList(values []int) string {
+	items := make([]string, len(values))
+	for i, value := range values {
+		items[i] = strconv.Itoa(value)
+	}
+	return "[" + strings.Join(items, ", ") + "]"
+}
+
+func renderTrendChart(options string) (string, error) {
+	return renderChart(trendWidth, trendHeight, options)
+}
+
+const simpleOptsTrendFlow = `{
+  "backgroundColor": "white",
+  "grid": { "left": 40, "top": 10, "bottom": 25, "right": 20 },
+  "xAxis": { "type": "category", "data": %s, "axisLabel": { "fontSize": 8 } },
+  "yAxis": { "type": "value", "minInterval": 1, "axisLabel": { "fontSize": 8 } },
+  "series": [
+    { "name": "Created", "type": "line", "showSymbol": false, "data": %s, "itemStyle": { "color": "#D62728" } },
+    { "name": "Left", "type": "line", "showSymbol": false, "data": %s, "itemStyle": { "color": "#3CB371" } },
+    { "name": "Returned", "type": "line", "showSymbol": false, "data": %s, "itemStyle": { "color": "#FF7F0E" } }
+  ]
+}`
+
+const simpleOptsTrendBacklog = `{
+  "backgroundColor": "white",
+  "grid": { "left": 40, "top": 10, "bottom": 25, "right": 20 },
+  "xAxis": { "type": "category", "data": %s, "axisLabel": { "fontSize": 8 } },
+  "yAxis": { "type": "value", "minInterval": 1, "axisLabel": { "fontSize": 8 } },
+  "series": [
+    { "name": "Open", "type": "line", "showSymbol": false, "data": %s, "areaStyle": { "color": "#B3CDF5" },
+      "itemStyle": { "color": "#015CE6" } }
+  ]
+}`
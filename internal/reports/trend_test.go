@@ -0,0 +1,73 @@
+package reports
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+)
+
+// trendIssue returns an issue created on the day given, in its current status, with one history
+// per change: field, from and to, on the day given.
+func trendIssue(key, created, status string, changes ...[4]string) jira.Issue {
+	createdAt, _ := time.Parse(time.DateOnly, created)
+	issue := jira.Issue{Key: key, Fields: &jira.IssueFields{Created: jira.Time(createdAt),
+		Status: &jira.Status{Name: status}}, Changelog: &jira.Changelog{}}
+	for _, change := range changes {
+		at, _ := time.Parse(time.DateOnly, change[0])
+		issue.Changelog.Histories = append(issue.Changelog.Histories, jira.ChangelogHistory{
+			Created: at.Format(jiraTimeLayout),
+			Items:   []jira.ChangelogItems{{Field: change[1], FromString: change[2], ToString: change[3]}},
+		})
+	}
+	return issue
+}
+
+func TestBuildBugTrend(t *testing.T) {
+	excluded := []string{"ON_QA", "QE Review"}
+	issues := []jira.Issue{
+		// Open before the window and still open.
+		trendIssue("OCPBUGS-1", "2025-01-10", "New"),
+		// Open before the window, moved to QE in the first week.
+		trendIssue("OCPBUGS-2", "2025-01-10", "ON_QA", [4]string{"2025-03-04", "status", "Assigned", "ON_QA"}),
+		// Created in the second week, back from QE in the third.
+		trendIssue("OCPBUGS-3", "2025-03-10", "Assigned",
+			[4]string{"2025-03-11", "status", "New", "QE Review"},
+			[4]string{"2025-03-18", "status", "QE Review", "Assigned"}),
+		// Resolved before the window, reopened in the third week.
+		trendIssue("OCPBUGS-4", "2025-01-10", "New",
+			[4]string{"2025-02-01", "resolution", "", "Done"},
+			[4]string{"2025-03-19", "resolution", "Done", ""}),
+		// Created in QE, entering the queue for the first time in the second week.
+		trendIssue("OCPBUGS-5", "2025-02-01", "New", [4]string{"2025-03-12", "status", "on_qa", "New"}),
+	}
+	from := time.Date(2025, time.March, 3, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, time.March, 20, 0, 0, 0, 0, time.UTC)
+
+	trend := buildBugTrend(issues, excluded, from, to)
+	if trend.OpenBefore != 2 {
+		t.Errorf("OpenBefore = %d, want 2", trend.OpenBefore)
+	}
+	for _, series := range []struct {
+		name      string
+		got, want []int
+	}{
+		{"Created", trend.Created, []int{0, 2, 0}},
+		{"Left", trend.Left, []int{1, 1, 0}},
+		{"Returned", trend.Returned, []int{0, 0, 2}},
+		{"Open", trend.Open, []int{1, 2, 4}},
+	} {
+		if !slices.Equal(series.got, series.want) {
+			t.Errorf("%s = %v, want %v", series.name, series.got, series.want)
+		}
+	}
+	markdown, err := trend.markdown()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Backlog growing: 2 open on 2025-03-03, 4 on 2025-03-20 (2 created, 2 left, 2 returned)"; !strings.Contains(markdown, want) {
+		t.Errorf("markdown = %q, want %q", markdown, want)
+	}
+}
@@ -0,0 +1,76 @@
+// Package sanitize filters the HTML rendered from the report markdown, which holds text from
+// Jira and from the models, down to the markup the reports produce.
+package sanitize
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// allowed lists the tags kept, with their allowed attributes: the markup goldmark renders for
+// GitHub flavored markdown and the tags the reports write themselves.
+var allowed = map[string][]string{
+	"a": {"href", "title"}, "p": nil, "br": nil, "hr": nil, "blockquote": nil,
+	"h1": nil, "h2": nil, "h3": nil, "h4": nil, "h5": nil, "h6": nil,
+	"ul": nil, "ol": {"start"}, "li": nil,
+	"strong": nil, "em": nil, "b": nil, "i": nil, "del": nil, "code": nil, "pre": nil,
+	"table": nil, "thead": nil, "tbody": nil, "tr": nil, "th": {"align", "style"}, "td": {"align", "style"},
+	"span": {"style"}, "img": {"src", "alt", "title", "width", "height"},
+	"details": nil, "summary": nil,
+}
+
+var (
+	// safeStyle only allows the colors and alignments the reports set.
+	safeStyle = regexp.MustCompile(`^(\s*(color|background-color|text-align)\s*:\s*#?[a-zA-Z0-9]+\s*;?)+\s*$`)
+	// safeImage is an embedded chart, or its inline part once moved out of an email body.
+	safeImage = regexp.MustCompile(`^(data:image/[a-z+]+;base64,[A-Za-z0-9+/=]+|cid:[^\s"]+)$`)
+	safeLink  = regexp.MustCompile(`^(?i)(https?://|mailto:|[/#?])`)
+)
+
+// HTML returns the allowed tags and attributes of s, with everything else escaped so it is
+// shown as text.
+func HTML(s string) string {
+	var sb strings.Builder
+	tokenizer := html.NewTokenizer(strings.NewReader(s))
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			return sb.String()
+		}
+		token := tokenizer.Token()
+		switch tokenType {
+		case html.TextToken:
+			sb.WriteString(html.EscapeString(token.Data))
+		case html.StartTagToken, html.SelfClosingTagToken, html.EndTagToken:
+			attributes, ok := allowed[token.Data]
+			if !ok {
+				sb.WriteString(html.EscapeString(token.String()))
+				continue
+			}
+			token.Attr = filterAttributes(token.Attr, attributes)
+			sb.WriteString(token.String())
+		case html.ErrorToken, html.CommentToken, html.DoctypeToken:
+		}
+	}
+}
+
+func filterAttributes(attributes []html.Attribute, names []string) []html.Attribute {
+	var kept []html.Attribute
+	for _, attribute := range attributes {
+		if attribute.Namespace != "" || !slices.Contains(names, attribute.Key) {
+			continue
+		}
+		value := strings.TrimSpace(attribute.Val)
+		switch {
+		case attribute.Key == "style" && !safeStyle.MatchString(value),
+			attribute.Key == "src" && !safeImage.MatchString(value),
+			attribute.Key == "href" && !safeLink.MatchString(value):
+			continue
+		}
+		kept = append(kept, attribute)
+	}
+	return kept
+}
@@ -0,0 +1,61 @@
+package sanitize
+
+import "testing"
+
+func TestHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "report markup",
+			in: `<p><span style="background-color:red; color:white">RED</span><br>` +
+				`<img src="data:image/jpeg;base64,AAEC" width="600" height="300"></p>`,
+			want: `<p><span style="background-color:red; color:white">RED</span><br>` +
+				`<img src="data:image/jpeg;base64,AAEC" width="600" height="300"></p>`,
+		},
+		{
+			name: "details and links",
+			in:   `<details><summary>Storage (2)</summary><ul><li><a href="https://issues.example.com/browse/CNF-1">CNF-1</a></li></ul></details>`,
+			want: `<details><summary>Storage (2)</summary><ul><li><a href="https://issues.example.com/browse/CNF-1">CNF-1</a></li></ul></details>`,
+		},
+		{
+			name: "inline email image",
+			in:   `<img src="cid:chart1.abc@example.com">`,
+			want: `<img src="cid:chart1.abc@example.com">`,
+		},
+		{
+			name: "script in a summary",
+			in:   `<p>CNF-1: <script>alert("x")</script></p>`,
+			want: `<p>CNF-1: &lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt;</p>`,
+		},
+		{
+			name: "event handler and unsafe style",
+			in:   `<span style="background:url(javascript:alert(1))" onclick="alert(1)">x</span><img src=x onerror=alert(1)>`,
+			want: `<span>x</span><img>`,
+		},
+		{
+			name: "javascript link",
+			in:   `<a href="javascript:alert(1)">click</a>`,
+			want: `<a>click</a>`,
+		},
+		{
+			name: "unknown tags",
+			in:   `<iframe src="https://example.com"></iframe><style>p{}</style>`,
+			want: `&lt;iframe src=&#34;https://example.com&#34;&gt;&lt;/iframe&gt;&lt;style&gt;p{}&lt;/style&gt;`,
+		},
+		{
+			name: "text is escaped again",
+			in:   `a &lt;b&gt; &amp; c<!-- comment -->`,
+			want: `a &lt;b&gt; &amp; c`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HTML(tt.in); got != tt.want {
+				t.Errorf("HTML(%q) =\n%s\nwant\n%s", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package server
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	// maxCacheEntries bounds the number of pages kept, as every set of parameters requested
+	// by a client gets its own page.
+	maxCacheEntries = 32
+	// idleRefreshes is the number of refresh intervals after which a page nobody requested is
+	// dropped instead of being rebuilt.
+	idleRefreshes = 3
+)
+
+// page is a report rendered from Jira data at a point in time.
+type page struct {
+	Markdown string
+	JSON     []byte
+	BuiltAt  time.Time
+}
+
+type buildFunc func(ctx context.Context) (*page, error)
+
+// cacheEntry holds the latest page of one report. build is serialized by buildMu so
+// concurrent requests for a missing page trigger a single Jira query.
+type cacheEntry struct {
+	build   buildFunc
+	buildMu sync.Mutex
+	// pinned pages are never dropped, they back the gauges of /metrics.
+	pinned bool
+	// lastUsed is the time of the last request of the page, guarded by the cache mutex.
+	lastUsed time.Time
+
+	mu   sync.RWMutex
+	page *page
+}
+
+// cache keeps the pages of the reports requested recently, keyed by report parameters.
+type cache struct {
+	mu         sync.Mutex
+	entries    map[string]*cacheEntry
+	pinned     map[string]bool
+	maxEntries int
+}
+
+// newCache returns a cache of at most maxEntries pages besides the pinned ones.
+func newCache(maxEntries int, pinned ...string) *cache {
+	c := &cache{entries: map[string]*cacheEntry{}, pinned: map[string]bool{}, maxEntries: maxEntries}
+	for _, key := range pinned {
+		c.pinned[key] = true
+	}
+	return c
+}
+
+// get returns the cached page of key, building it on first use. Failed builds are not
+// cached, so the next request retries.
+func (c *cache) get(ctx context.Context, key string, build buildFunc) (*page, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if !ok {
+		c.evict()
+		entry = &cacheEntry{build: build, pinned: c.pinned[key]}
+		c.entries[key] = entry
+	}
+	entry.lastUsed = time.Now()
+	c.mu.Unlock()
+
+	if p := entry.current(); p != nil {
+		return p, nil
+	}
+
+	entry.buildMu.Lock()
+	defer entry.buildMu.Unlock()
+	if p := entry.current(); p != nil {
+		return p, nil
+	}
+	p, err := entry.rebuild(ctx)
+	if err != nil {
+		// Forget keys that never built, so invalid parameters are not refreshed forever.
+		c.mu.Lock()
+		if c.entries[key] == entry {
+			delete(c.entries, key)
+		}
+		c.mu.Unlock()
+	}
+	return p, err
+}
+
+// evict drops the least recently used pages that are not pinned until a new one fits. It is
+// called with the cache mutex held.
+func (c *cache) evict() {
+	for {
+		unpinned := 0
+		oldestKey := ""
+		var oldest time.Time
+		for key, entry := range c.entries {
+			if entry.pinned {
+				continue
+			}
+			unpinned++
+			if oldestKey == "" || entry.lastUsed.Before(oldest) {
+				oldestKey, oldest = key, entry.lastUsed
+			}
+		}
+		if unpinned < c.maxEntries || oldestKey == "" {
+			return
+		}
+		delete(c.entries, oldestKey)
+	}
+}
+
+func (e *cacheEntry) current() *page {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.page
+}
+
+// rebuild builds a new page and replaces the cached one on success. Readers keep getting the
+// previous page while the build runs.
+func (e *cacheEntry) rebuild(ctx context.Context) (*page, error) {
+	p, err := e.build(ctx)
+	if err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	e.page = p
+	e.mu.Unlock()
+	return p, nil
+}
+
+// refresh rebuilds the cached pages each interval until ctx is done. A failed refresh keeps
+// the previous page. Pages not requested for idleRefreshes intervals are dropped, unless they
+// are pinned.
+func (c *cache) refresh(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		idleSince := time.Now().Add(-idleRefreshes * interval)
+		c.mu.Lock()
+		keys := make([]string, 0, len(c.entries))
+		entries := make([]*cacheEntry, 0, len(c.entries))
+		for key, entry := range c.entries {
+			if !entry.pinned && entry.lastUsed.Before(idleSince) {
+				delete(c.entries, key)
+				continue
+			}
+			keys = append(keys, key)
+			entries = append(entries, entry)
+		}
+		c.mu.Unlock()
+
+		for i, entry := range entries {
+			entry.buildMu.Lock()
+			_, err := entry.rebuild(ctx)
+			entry.buildMu.Unlock()
+			if err != nil {
+				log.Printf("Cannot refresh %s, keeping the previous version, err:%v", keys[i], err)
+			}
+		}
+	}
+}
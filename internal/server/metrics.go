@@ -0,0 +1,111 @@
+package server
+
+import (
+	"time"
+
+	"github.com/edcdavid/jira-helper/internal/reports"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const metricsNamespace = "jira_helper"
+
+// metrics are the gauges exported on /metrics. They are set each time a report or the bug
+// status is built, so they follow the refresh interval of the cache.
+type metrics struct {
+	registry *prometheus.Registry
+
+	issuesByColor  *prometheus.GaugeVec
+	issuesByStatus *prometheus.GaugeVec
+	staleIssues    *prometheus.GaugeVec
+	bugStatus      *prometheus.GaugeVec
+	bugStatusGroup *prometheus.GaugeVec
+	bugStatusTotal *prometheus.GaugeVec
+	bugStatusRAG   *prometheus.GaugeVec
+	lastRefresh    *prometheus.GaugeVec
+}
+
+func newMetrics() *metrics {
+	m := &metrics{
+		registry: prometheus.NewRegistry(),
+		issuesByColor: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "report_issues",
+			Help:      "Number of report issues per health color (Green, Yellow, Red or None).",
+		}, []string{"release", "customerFacing", "color"}),
+		issuesByStatus: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "report_issues_by_status",
+			Help:      "Number of report issues per workflow status.",
+		}, []string{"release", "customerFacing", "status"}),
+		staleIssues: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "report_stale_issues",
+			Help:      "Number of report issues whose latest status summary is older than --stale-after.",
+		}, []string{"release", "customerFacing"}),
+		bugStatus: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "bugstatus_issues",
+			Help:      "Number of issues per component matched by a bugstatus.yml filter counted by component.",
+		}, []string{"filter", "component"}),
+		bugStatusGroup: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "bugstatus_group_issues",
+			Help:      "Number of issues per group matched by a bugstatus.yml filter counted by another groupBy field.",
+		}, []string{"filter", "groupBy", "group"}),
+		bugStatusTotal: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "bugstatus_distinct_issues",
+			Help:      "Number of distinct issues matched by a bugstatus.yml filter.",
+		}, []string{"filter"}),
+		bugStatusRAG: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "bugstatus_rag",
+			Help:      "RAG status of a bugstatus.yml filter with thresholds: 1 green, 2 yellow, 3 red.",
+		}, []string{"filter"}),
+		lastRefresh: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "last_refresh_timestamp_seconds",
+			Help:      "Time of the last successful build of a report from Jira.",
+		}, []string{"report"}),
+	}
+	m.registry.MustRegister(m.issuesByColor, m.issuesByStatus, m.staleIssues, m.bugStatus, m.bugStatusGroup,
+		m.bugStatusTotal, m.bugStatusRAG, m.lastRefresh)
+	return m
+}
+
+// setReport replaces the gauges of the release and customerFacing of a report summary.
+func (m *metrics) setReport(summary *reports.ReportSummary) {
+	labels := prometheus.Labels{"release": summary.Release, "customerFacing": summary.CustomerFacing}
+	m.issuesByColor.DeletePartialMatch(labels)
+	m.issuesByStatus.DeletePartialMatch(labels)
+	for color, count := range summary.Colors {
+		m.issuesByColor.WithLabelValues(summary.Release, summary.CustomerFacing, color).Set(float64(count))
+	}
+	for status, count := range summary.Statuses {
+		m.issuesByStatus.WithLabelValues(summary.Release, summary.CustomerFacing, status).Set(float64(count))
+	}
+	m.staleIssues.WithLabelValues(summary.Release, summary.CustomerFacing).Set(float64(summary.Stale))
+	m.lastRefresh.WithLabelValues("report").Set(float64(summary.GeneratedAt.Unix()))
+}
+
+// setBugStatus replaces the bug status gauges.
+func (m *metrics) setBugStatus(counts []reports.BugStatusCount, builtAt time.Time) {
+	m.bugStatus.Reset()
+	m.bugStatusGroup.Reset()
+	m.bugStatusTotal.Reset()
+	m.bugStatusRAG.Reset()
+	for _, count := range counts {
+		for group, value := range count.Groups {
+			if count.GroupBy == reports.DefaultGroupBy {
+				m.bugStatus.WithLabelValues(count.Name, group).Set(float64(value))
+			} else {
+				m.bugStatusGroup.WithLabelValues(count.Name, count.GroupBy, group).Set(float64(value))
+			}
+		}
+		m.bugStatusTotal.WithLabelValues(count.Name).Set(float64(count.Total))
+		if rank := reports.RAGRank(count.RAG); rank > 0 {
+			m.bugStatusRAG.WithLabelValues(count.Name).Set(float64(rank))
+		}
+	}
+	m.lastRefresh.WithLabelValues("bugstatus").Set(float64(builtAt.Unix()))
+}
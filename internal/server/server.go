@@ -0,0 +1,357 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/edcdavid/jira-helper/internal/jirahelper"
+	"github.com/edcdavid/jira-helper/internal/reports"
+	"github.com/edcdavid/jira-helper/internal/sanitize"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+const (
+	readHeaderTimeout = 10 * time.Second
+	shutdownTimeout   = 30 * time.Second
+	// buildTimeout bounds the Jira queries and rendering of one page.
+	buildTimeout = 5 * time.Minute
+)
+
+// releasePattern is the form of the releases accepted from the query string, as the release
+// ends up in the JQL of the report.
+var releasePattern = regexp.MustCompile(`^\d+\.\d+$`)
+
+// Options configures Run.
+type Options struct {
+	Listen              string
+	JiraURL             string
+	PersonalAccessToken string
+	// Refresh is the interval at which the cached reports are rebuilt from Jira.
+	Refresh time.Duration
+	// Release and CustomerFacing are used by /report and /api/report.json when the request
+	// does not set them.
+	Release        string
+	CustomerFacing string
+	// ReleaseDate and FromDate are used by /bugstatus when the request does not set them.
+	ReleaseDate string
+	FromDate    string
+	StaleAfter  time.Duration
+	// MetricsReleases and MetricsCustomerFacing are the reports built at startup, and then
+	// refreshed, so their gauges are always available on /metrics.
+	MetricsReleases       []string
+	MetricsCustomerFacing []string
+	// Calendar gives the milestones shown in the report of its releases.
+	Calendar reports.ReleaseCalendar
+}
+
+type server struct {
+	opts     *Options
+	cache    *cache
+	metrics  *metrics
+	markdown goldmark.Markdown
+}
+
+var pageTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>body { font-family: sans-serif; margin: 2em; } img { max-width: 100%; }</style>
+</head>
+<body>
+<p><small>Generated {{.BuiltAt.Format "2006-01-02 15:04:05 MST"}}</small></p>
+{{.Body}}
+</body>
+</html>
+`))
+
+var indexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>jira-helper</title></head>
+<body>
+<ul>
+<li><a href="/report?release={{.Release}}&customerFacing={{.CustomerFacing}}">Report</a></li>
+<li><a href="/bugstatus?releaseDate={{.ReleaseDate}}&fromDate={{.FromDate}}">Bug status</a></li>
+<li><a href="/api/report.json?release={{.Release}}&customerFacing={{.CustomerFacing}}">Report (JSON)</a></li>
+<li><a href="/metrics">Metrics</a></li>
+</ul>
+</body>
+</html>
+`))
+
+// Run serves the reports over HTTP until ctx is done, then shuts the server down gracefully.
+// Pages are built on first request and rebuilt every refresh interval, so requests are served
+// from memory.
+func Run(ctx context.Context, opts *Options) error {
+	var pinned []string
+	for _, release := range opts.MetricsReleases {
+		for _, customerFacing := range opts.MetricsCustomerFacing {
+			pinned = append(pinned, reportKey(release, customerFacing))
+		}
+	}
+	pinned = append(pinned, bugStatusKey(opts.ReleaseDate, opts.FromDate, false))
+	s := &server{
+		opts:    opts,
+		cache:   newCache(maxCacheEntries, pinned...),
+		metrics: newMetrics(),
+		// The raw HTML of the reports is rendered, and filtered by sanitize.HTML.
+		markdown: goldmark.New(
+			goldmark.WithExtensions(extension.GFM),
+			goldmark.WithRendererOptions(html.WithUnsafe()),
+		),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.handleIndex)
+	mux.HandleFunc("GET /report", s.handleReport)
+	mux.HandleFunc("GET /bugstatus", s.handleBugStatus)
+	mux.HandleFunc("GET /api/report.json", s.handleReportJSON)
+	mux.Handle("GET /metrics", promhttp.HandlerFor(s.metrics.registry, promhttp.HandlerOpts{}))
+
+	srv := &http.Server{
+		Addr:              opts.Listen,
+		Handler:           mux,
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	refreshCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if opts.Refresh > 0 {
+		go s.cache.refresh(refreshCtx, opts.Refresh)
+	}
+	go s.prebuild(refreshCtx)
+
+	errs := make(chan error, 1)
+	go func() {
+		log.Printf("Listening on %s", opts.Listen)
+		errs <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Print("Shutting down")
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer shutdownCancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errs; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (s *server) handleIndex(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := indexTemplate.Execute(w, s.opts); err != nil {
+		log.Printf("Cannot render index, err:%v", err)
+	}
+}
+
+func (s *server) handleReport(w http.ResponseWriter, r *http.Request) {
+	p, ok := s.handleReportPage(w, r)
+	if !ok {
+		return
+	}
+	s.writeHTML(w, "Report", p)
+}
+
+func (s *server) handleReportJSON(w http.ResponseWriter, r *http.Request) {
+	p, ok := s.handleReportPage(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(p.JSON)
+}
+
+func (s *server) handleBugStatus(w http.ResponseWriter, r *http.Request) {
+	releaseDate := queryOr(r, "releaseDate", s.opts.ReleaseDate)
+	fromDate := queryOr(r, "fromDate", s.opts.FromDate)
+	for _, date := range []string{releaseDate, fromDate} {
+		if _, err := time.Parse(time.DateOnly, date); err != nil {
+			http.Error(w, fmt.Sprintf("invalid date %q, expected YYYY-MM-DD", date), http.StatusBadRequest)
+			return
+		}
+	}
+
+	listIssues := r.URL.Query().Get("issues") == "true"
+
+	p, err := s.bugStatusPage(r.Context(), releaseDate, fromDate, listIssues)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	s.writeHTML(w, "Bug status", p)
+}
+
+// handleReportPage returns the report of the release and customerFacing of the request. It
+// writes the error response and returns false on failure.
+func (s *server) handleReportPage(w http.ResponseWriter, r *http.Request) (*page, bool) {
+	release := queryOr(r, "release", s.opts.Release)
+	if !s.validRelease(release) {
+		http.Error(w, fmt.Sprintf("release %q not supported, use a release such as 4.20", release),
+			http.StatusBadRequest)
+		return nil, false
+	}
+	customerFacing := queryOr(r, "customerFacing", s.opts.CustomerFacing)
+	switch customerFacing {
+	case "yes", "no", "both":
+	default:
+		http.Error(w, fmt.Sprintf("customerFacing %q not supported, use yes, no or both", customerFacing),
+			http.StatusBadRequest)
+		return nil, false
+	}
+
+	p, err := s.reportPage(r.Context(), release, customerFacing)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return nil, false
+	}
+	return p, true
+}
+
+// validRelease tells whether a release can be put in the report query: a release number, or
+// one of the releases configured or in the calendar.
+func (s *server) validRelease(release string) bool {
+	return releasePattern.MatchString(release) || release == s.opts.Release ||
+		slices.Contains(s.opts.MetricsReleases, release) || s.opts.Calendar.Find(release) != nil
+}
+
+func reportKey(release, customerFacing string) string {
+	return "report|" + release + "|" + customerFacing
+}
+
+func bugStatusKey(releaseDate, fromDate string, listIssues bool) string {
+	return "bugstatus|" + releaseDate + "|" + fromDate + "|" + strconv.FormatBool(listIssues)
+}
+
+// exportsMetrics tells whether the gauges of a report are exported. Only the configured reports
+// are, so the releases requested by clients do not add series to /metrics.
+func (s *server) exportsMetrics(release, customerFacing string) bool {
+	return slices.Contains(s.opts.MetricsReleases, release) && slices.Contains(s.opts.MetricsCustomerFacing, customerFacing)
+}
+
+// reportPage returns the cached report of a release, and updates its gauges when it is built
+// and exported.
+func (s *server) reportPage(ctx context.Context, release, customerFacing string) (*page, error) {
+	opts := reports.ReportOptions{
+		JiraURL:             s.opts.JiraURL,
+		PersonalAccessToken: s.opts.PersonalAccessToken,
+		Release:             release,
+		CustomerFacing:      customerFacing,
+		StaleAfter:          s.opts.StaleAfter,
+		Milestones:          s.opts.Calendar.Find(release),
+	}
+	key := reportKey(release, customerFacing)
+	p, err := s.cache.get(ctx, key, func(ctx context.Context) (*page, error) {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), buildTimeout)
+		defer cancel()
+		issues, err := reports.FetchReportIssues(ctx, &opts)
+		if err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		var buf bytes.Buffer
+		if err := reports.RenderMarkdownReport(&buf, issues, nil, &opts); err != nil {
+			return nil, err
+		}
+		summary := reports.BuildReportSummary(issues, &opts, now)
+		summaryJSON, err := json.MarshalIndent(summary, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		if s.exportsMetrics(release, customerFacing) {
+			s.metrics.setReport(&summary)
+		}
+		return &page{Markdown: buf.String(), JSON: summaryJSON, BuiltAt: now}, nil
+	})
+	if err != nil {
+		log.Printf("Cannot build %s, err:%v", key, err)
+	}
+	return p, err
+}
+
+// bugStatusPage returns the cached bug status of the dates, with the issues of each group when
+// listIssues is set. The gauges follow the default dates only, so they keep a single series per
+// filter and group.
+func (s *server) bugStatusPage(ctx context.Context, releaseDate, fromDate string, listIssues bool) (*page, error) {
+	key := bugStatusKey(releaseDate, fromDate, listIssues)
+	p, err := s.cache.get(ctx, key, func(ctx context.Context) (*page, error) {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), buildTimeout)
+		defer cancel()
+		client, err := jirahelper.NewClient(s.opts.JiraURL, s.opts.PersonalAccessToken)
+		if err != nil {
+			return nil, err
+		}
+		counts, err := reports.FetchBugStatusCounts(ctx, client, releaseDate, fromDate)
+		if err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		if releaseDate == s.opts.ReleaseDate && fromDate == s.opts.FromDate {
+			s.metrics.setBugStatus(counts, now)
+		}
+		var buf bytes.Buffer
+		if err := reports.RenderBugStatusReport(&buf, counts, s.opts.JiraURL, listIssues); err != nil {
+			return nil, err
+		}
+		return &page{Markdown: buf.String(), BuiltAt: now}, nil
+	})
+	if err != nil {
+		log.Printf("Cannot build %s, err:%v", key, err)
+	}
+	return p, err
+}
+
+// prebuild builds the pages exported as metrics, so /metrics is populated without waiting for
+// a page request. Failures are logged and retried on the next request.
+func (s *server) prebuild(ctx context.Context) {
+	for _, release := range s.opts.MetricsReleases {
+		for _, customerFacing := range s.opts.MetricsCustomerFacing {
+			_, _ = s.reportPage(ctx, release, customerFacing)
+		}
+	}
+	_, _ = s.bugStatusPage(ctx, s.opts.ReleaseDate, s.opts.FromDate, false)
+}
+
+func (s *server) writeHTML(w http.ResponseWriter, title string, p *page) {
+	var body bytes.Buffer
+	if err := s.markdown.Convert([]byte(p.Markdown), &body); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	err := pageTemplate.Execute(w, struct {
+		Title   string
+		BuiltAt time.Time
+		Body    template.HTML
+	}{title, p.BuiltAt, template.HTML(sanitize.HTML(body.String()))}) //nolint:gosec
+	if err != nil {
+		log.Printf("Cannot render %s, err:%v", title, err)
+	}
+}
+
+func queryOr(r *http.Request, name, fallback string) string {
+	if value := r.URL.Query().Get(name); value != "" {
+		return value
+	}
+	return fallback
+}
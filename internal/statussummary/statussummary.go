@@ -0,0 +1,138 @@
+package statussummary
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Entry is one dated status update found in a status summary field.
+type Entry struct {
+	Date time.Time
+	// Layout is the Go time layout the date was written with, so new entries can reuse it.
+	Layout string
+	// Header is the beginning of the entry line up to the text, including the date and its
+	// markup, for example "*5/12:* ".
+	Header  string
+	RawDate string
+	Text    string
+}
+
+const months = `(?:Jan|Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Sept|Oct|Nov|Dec)[a-z]*\.?`
+
+// entryStart matches a date at the beginning of a line, optionally preceded by wiki markup
+// (bullets, headings, bold or italics) and followed by a separator.
+var entryStart = regexp.MustCompile(`(?im)^[\s*_#+\-]*(?:h\d\.\s*)?[*_]*(` +
+	`\d{4}-\d{1,2}-\d{1,2}|` +
+	`\d{1,2}/\d{1,2}(?:/\d{2,4})?|` +
+	months + `\s+\d{1,2}(?:st|nd|rd|th)?(?:,?\s+\d{4})?|` +
+	`\d{1,2}(?:st|nd|rd|th)?\s+` + months + `(?:,?\s+\d{4})?` +
+	`)\b[*_]*\s*[:\-–]?[*_]*`)
+
+var (
+	ordinalSuffix = regexp.MustCompile(`(\d)(?:st|nd|rd|th)\b`)
+	// sept is the four letter abbreviation of September, which no time layout parses.
+	sept   = regexp.MustCompile(`(?i)\bsept\b`)
+	spaces = regexp.MustCompile(`\s+`)
+)
+
+// layouts are tried in order; zero-padded variants come first so the detected layout
+// reproduces the original formatting.
+var layouts = []string{
+	"2006-01-02", "2006-1-2",
+	"01/02/2006", "1/2/2006", "01/02/06", "1/2/06", "01/02", "1/2",
+	"Jan 2 2006", "January 2 2006", "Jan 2", "January 2",
+	"2 Jan 2006", "2 January 2006", "2 Jan", "2 January",
+}
+
+// Parse splits a status summary into its dated entries, most recent first. Dates without a
+// year are assumed to be in the year of now, or the previous year when that would put them
+// more than a month in the future.
+func Parse(text string, now time.Time) []Entry {
+	text = strings.ReplaceAll(text, "\r", "")
+	matches := entryStart.FindAllStringSubmatchIndex(text, -1)
+
+	var entries []Entry
+	for i, m := range matches {
+		date, layout, ok := parseDate(text[m[2]:m[3]], now)
+		if !ok {
+			continue
+		}
+		end := len(text)
+		if i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+		entries = append(entries, Entry{
+			Date:    date,
+			Layout:  layout,
+			Header:  strings.TrimLeft(text[m[0]:m[1]], "\n"),
+			RawDate: text[m[2]:m[3]],
+			Text:    strings.TrimSpace(text[m[1]:end]),
+		})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Date.After(entries[j].Date)
+	})
+	return entries
+}
+
+// Latest returns the most recent entry of a status summary.
+func Latest(text string, now time.Time) (Entry, bool) {
+	entries := Parse(text, now)
+	if len(entries) == 0 {
+		return Entry{}, false
+	}
+	return entries[0], true
+}
+
+// DefaultHeader is used for the first entry of an empty status summary.
+const DefaultHeader = "01/02/2006: "
+
+// Prepend adds a new entry dated now on top of a status summary. The date is written with
+// the layout and markup of the most recent existing entry.
+func Prepend(text, message string, now time.Time) string {
+	header := now.Format(DefaultHeader)
+	if latest, ok := Latest(text, now); ok {
+		header = strings.Replace(latest.Header, latest.RawDate, now.Format(latest.Layout), 1)
+		if !strings.HasSuffix(header, " ") {
+			header += " "
+		}
+	}
+	entry := header + strings.TrimSpace(message)
+	if strings.TrimSpace(text) == "" {
+		return entry
+	}
+	return entry + "\n" + text
+}
+
+// Normalize cleans up a status summary before it is sent to a model.
+func Normalize(text string) string {
+	text = strings.ReplaceAll(text, "\r", "")
+	text = strings.ReplaceAll(text, "\n\n", "\n")
+	text = strings.ReplaceAll(text, "\t", " ")
+	return strings.ReplaceAll(text, " / ", "/")
+}
+
+func parseDate(raw string, now time.Time) (time.Time, string, bool) {
+	normalized := ordinalSuffix.ReplaceAllString(raw, "$1")
+	normalized = strings.NewReplacer(",", " ", ".", "").Replace(normalized)
+	normalized = sept.ReplaceAllString(normalized, "Sep")
+	normalized = strings.TrimSpace(spaces.ReplaceAllString(normalized, " "))
+
+	for _, layout := range layouts {
+		date, err := time.ParseInLocation(layout, normalized, now.Location())
+		if err != nil {
+			continue
+		}
+		if !strings.Contains(layout, "06") {
+			date = date.AddDate(now.Year(), 0, 0)
+			if date.After(now.AddDate(0, 1, 0)) {
+				date = date.AddDate(-1, 0, 0)
+			}
+		}
+		return date, layout, true
+	}
+	return time.Time{}, "", false
+}
@@ -0,0 +1,119 @@
+package statussummary
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	now := time.Date(2025, time.October, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		text  string
+		dates []string
+		texts []string
+	}{
+		{
+			name:  "slashes without year",
+			text:  "5/12: blocked on kernel fix\n4/28: started",
+			dates: []string{"2025-05-12", "2025-04-28"},
+			texts: []string{"blocked on kernel fix", "started"},
+		},
+		{
+			name:  "iso dates sorted most recent first",
+			text:  "2025-08-01: first\n2025-09-01: second",
+			dates: []string{"2025-09-01", "2025-08-01"},
+			texts: []string{"second", "first"},
+		},
+		{
+			name:  "full month names",
+			text:  "September 3: waiting on QE\nAugust 1: started",
+			dates: []string{"2025-09-03", "2025-08-01"},
+			texts: []string{"waiting on QE", "started"},
+		},
+		{
+			name:  "full month name with year",
+			text:  "September 3, 2025: done",
+			dates: []string{"2025-09-03"},
+			texts: []string{"done"},
+		},
+		{
+			name:  "sept abbreviation in any case",
+			text:  "Sept 3: upper\nsept. 2nd: lower\nSEPT 1: caps",
+			dates: []string{"2025-09-03", "2025-09-02", "2025-09-01"},
+			texts: []string{"upper", "lower", "caps"},
+		},
+		{
+			name:  "day before month",
+			text:  "3rd September 2025 - done",
+			dates: []string{"2025-09-03"},
+			texts: []string{"done"},
+		},
+		{
+			name:  "wiki markup",
+			text:  "* *10/1:* all good\nh3. 9/15 - on track",
+			dates: []string{"2025-10-01", "2025-09-15"},
+			texts: []string{"all good", "on track"},
+		},
+		{
+			name:  "future date without year is last year",
+			text:  "12/20: planned",
+			dates: []string{"2024-12-20"},
+			texts: []string{"planned"},
+		},
+		{
+			name: "no dated entry",
+			text: "all good",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := Parse(tt.text, now)
+			if len(entries) != len(tt.dates) {
+				t.Fatalf("Parse(%q) returned %d entries, want %d: %+v", tt.text, len(entries), len(tt.dates), entries)
+			}
+			for i, entry := range entries {
+				if got := entry.Date.Format(time.DateOnly); got != tt.dates[i] {
+					t.Errorf("entry %d date = %s, want %s", i, got, tt.dates[i])
+				}
+				if entry.Text != tt.texts[i] {
+					t.Errorf("entry %d text = %q, want %q", i, entry.Text, tt.texts[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLatest(t *testing.T) {
+	now := time.Date(2025, time.October, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		text   string
+		ok     bool
+		date   string
+		latest string
+	}{
+		{name: "most recent of unsorted entries", text: "August 1: old\nSeptember 30: new", ok: true,
+			date: "2025-09-30", latest: "new"},
+		{name: "sept entry", text: "Sept 12: kernel fix merged", ok: true, date: "2025-09-12",
+			latest: "kernel fix merged"},
+		{name: "empty", text: "", ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, ok := Latest(tt.text, now)
+			if ok != tt.ok {
+				t.Fatalf("Latest(%q) ok = %v, want %v", tt.text, ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+			if got := entry.Date.Format(time.DateOnly); got != tt.date {
+				t.Errorf("date = %s, want %s", got, tt.date)
+			}
+			if entry.Text != tt.latest {
+				t.Errorf("text = %q, want %q", entry.Text, tt.latest)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package statusupdate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+	"github.com/edcdavid/jira-helper/internal/jirahelper"
+	"github.com/edcdavid/jira-helper/internal/statussummary"
+	"github.com/edcdavid/jira-helper/internal/stringhelper"
+)
+
+var colors = []string{"Green", "Yellow", "Red"}
+
+// Options describes the change of the color and status summary of one issue.
+type Options struct {
+	Key string
+	// Color is Green, Yellow or Red, or empty to keep the current color.
+	Color string
+	// Message is the new status entry. When empty, Editor is opened with the current text.
+	Message string
+	Editor  string
+	DryRun  bool
+}
+
+// Set prepends a dated entry to the status summary of an issue and updates its color. With
+// DryRun, the changes are only printed to out.
+func Set(ctx context.Context, client *jira.Client, opts *Options, out io.Writer) error {
+	color, err := normalizeColor(opts.Color)
+	if err != nil {
+		return err
+	}
+
+	issue, resp, err := client.Issue.Get(ctx, opts.Key, &jira.GetQueryOptions{
+		Fields: strings.Join([]string{"summary", jirahelper.ColorField, jirahelper.StatusSummaryField}, ","),
+	})
+	if err != nil {
+		return jira.NewJiraError(resp, err)
+	}
+	currentText, _ := issue.Fields.Unknowns[jirahelper.StatusSummaryField].(string)
+	currentColor := ""
+	if value, ok := issue.Fields.Unknowns[jirahelper.ColorField].(map[string]any); ok {
+		currentColor, _ = value["value"].(string)
+	}
+
+	newText, err := newStatusSummary(currentText, opts, time.Now())
+	if err != nil {
+		return err
+	}
+
+	fields := map[string]any{}
+	if strings.TrimSpace(newText) != strings.TrimSpace(currentText) {
+		fields[jirahelper.StatusSummaryField] = newText
+	}
+	if color != "" && color != currentColor {
+		fields[jirahelper.ColorField] = map[string]string{"value": color}
+	}
+	if len(fields) == 0 {
+		fmt.Fprintf(out, "%s: nothing to change\n", opts.Key)
+		return nil
+	}
+
+	fmt.Fprintf(out, "%s: %s\n", opts.Key, issue.Fields.Summary)
+	if _, ok := fields[jirahelper.ColorField]; ok {
+		fmt.Fprintf(out, "Color: %s -> %s\n", valueOrNone(currentColor), color)
+	}
+	if _, ok := fields[jirahelper.StatusSummaryField]; ok {
+		fmt.Fprintf(out, "Status summary:\n%s", stringhelper.LineDiff(currentText, newText))
+	}
+	if opts.DryRun {
+		fmt.Fprintln(out, "Dry run, the issue was not updated")
+		return nil
+	}
+
+	resp, err = client.Issue.UpdateIssue(ctx, opts.Key, map[string]any{"fields": fields})
+	if err != nil {
+		return jira.NewJiraError(resp, err)
+	}
+	fmt.Fprintln(out, "Updated")
+	return nil
+}
+
+// newStatusSummary returns the status summary with the new entry on top. A buffer saved
+// unchanged from the editor keeps the current text, and a new entry left blank is rejected.
+func newStatusSummary(currentText string, opts *Options, now time.Time) (string, error) {
+	if opts.Message != "" {
+		if strings.TrimSpace(opts.Message) == "" {
+			return "", errBlankEntry
+		}
+		return statussummary.Prepend(currentText, opts.Message, now), nil
+	}
+	prefilled := statussummary.Prepend(currentText, "", now)
+	newText, err := edit(opts.Editor, prefilled)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(newText) == strings.TrimSpace(prefilled) {
+		return currentText, nil
+	}
+	if latest, ok := statussummary.Latest(newText, now); ok && latest.Text == "" {
+		return "", errBlankEntry
+	}
+	return newText, nil
+}
+
+var errBlankEntry = errors.New("the new status entry is blank, the issue was not updated")
+
+func normalizeColor(color string) (string, error) {
+	if color == "" {
+		return "", nil
+	}
+	for _, valid := range colors {
+		if strings.EqualFold(color, valid) {
+			return valid, nil
+		}
+	}
+	return "", fmt.Errorf("color %q not supported. Use %s", color, strings.Join(colors, ", "))
+}
+
+// edit opens the editor on a temporary file holding text, and returns the saved content.
+func edit(editor, text string) (string, error) {
+	if editor == "" {
+		return "", errors.New("no message given and $EDITOR is not set")
+	}
+	file, err := os.CreateTemp("", "jira-helper-status-*.txt")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.WriteString(text); err != nil {
+		file.Close()
+		return "", err
+	}
+	file.Close()
+
+	args := strings.Fields(editor)
+	cmd := exec.Command(args[0], append(args[1:], file.Name())...) //nolint:gosec
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("editor %s: %w", editor, err)
+	}
+
+	edited, err := os.ReadFile(file.Name())
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(edited), "\n"), nil
+}
+
+func valueOrNone(value string) string {
+	if value == "" {
+		return "none"
+	}
+	return value
+}
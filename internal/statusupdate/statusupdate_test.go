@@ -0,0 +1,83 @@
+package statusupdate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+	"github.com/edcdavid/jira-helper/internal/jirahelper"
+)
+
+// editorScript writes a shell script run as the editor on the status file.
+func editorScript(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "editor.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0o700); err != nil { //nolint:gosec
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSetWithEditor(t *testing.T) {
+	const currentText = "09/01/2026: on track"
+	tests := []struct {
+		name    string
+		script  string
+		color   string
+		wantErr error
+		want    string
+	}{
+		{name: "saved unchanged", script: "exit 0"},
+		{name: "saved unchanged with a color", script: "exit 0", color: "red"},
+		{name: "new entry typed", script: `sed -i '1s/$/waiting on QE/' "$1"`,
+			want: time.Now().Format("01/02/2006") + ": waiting on QE\n" + currentText},
+		{name: "new entry left blank", script: `sed -i '2s/$/ (edited)/' "$1"`, wantErr: errBlankEntry},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var updated map[string]any
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method {
+				case http.MethodGet:
+					_ = json.NewEncoder(w).Encode(map[string]any{"key": "CNF-1", "fields": map[string]any{
+						"summary":                     "summary of CNF-1",
+						jirahelper.StatusSummaryField: currentText,
+						jirahelper.ColorField:         map[string]string{"value": "Green"},
+					}})
+				case http.MethodPut:
+					var body struct {
+						Fields map[string]any `json:"fields"`
+					}
+					_ = json.NewDecoder(r.Body).Decode(&body)
+					updated = body.Fields
+					w.WriteHeader(http.StatusNoContent)
+				}
+			}))
+			defer server.Close()
+			client, err := jira.NewClient(server.URL, server.Client())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = Set(context.Background(), client, &Options{Key: "CNF-1", Color: tt.color,
+				Editor: editorScript(t, tt.script)}, io.Discard)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Set returned %v, want %v", err, tt.wantErr)
+			}
+			if got, _ := updated[jirahelper.StatusSummaryField].(string); got != tt.want {
+				t.Errorf("status summary written %q, want %q", got, tt.want)
+			}
+			if _, ok := updated[jirahelper.ColorField]; ok != (tt.color != "") {
+				t.Errorf("color written %v, want %q", updated[jirahelper.ColorField], tt.color)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package timehelper
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	Day  = 24 * time.Hour
+	Week = 7 * Day
+)
+
+// ParseDuration parses a Go duration, also accepting a number of days or weeks such as
+// 14d or 2w.
+func ParseDuration(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": Day, "w": Week} {
+		if number, ok := strings.CutSuffix(s, suffix); ok {
+			count, err := strconv.ParseFloat(number, 64)
+			if err != nil {
+				return 0, err
+			}
+			return time.Duration(count * float64(unit)), nil
+		}
+	}
+	return time.ParseDuration(s)
+}
+
+// Days returns the number of whole days of a duration.
+func Days(d time.Duration) int {
+	return int(d / Day)
+}
@@ -0,0 +1,148 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Target kinds.
+const (
+	Slack      = "slack"
+	GoogleChat = "googlechat"
+	Teams      = "teams"
+	Generic    = "generic"
+)
+
+const (
+	sendTimeout = 30 * time.Second
+	// maxChangesPerMessage keeps chat messages readable; the rest is summarized in one line.
+	maxChangesPerMessage = 30
+	maxErrorBody         = 512
+)
+
+// Target is a destination of the change notifications.
+type Target interface {
+	// Name identifies the target in the state file.
+	Name() string
+	Send(ctx context.Context, release string, changes []Change) error
+}
+
+type webhookTarget struct {
+	kind string
+	url  string
+}
+
+// NewTarget returns a target posting to an incoming webhook URL of the kind: slack,
+// googlechat, teams or generic.
+func NewTarget(kind, url string) (Target, error) {
+	switch kind {
+	case Slack, GoogleChat, Teams, Generic:
+	default:
+		return nil, fmt.Errorf("webhook kind %q not supported, use %s, %s, %s or %s", kind, Slack, GoogleChat, Teams, Generic)
+	}
+	if !strings.HasPrefix(url, "https://") && !strings.HasPrefix(url, "http://") {
+		return nil, fmt.Errorf("invalid %s webhook URL %q", kind, url)
+	}
+	return &webhookTarget{kind: kind, url: url}, nil
+}
+
+// Name is the kind and a hash of the URL, as webhook URLs embed their secret.
+func (t *webhookTarget) Name() string {
+	sum := sha256.Sum256([]byte(t.url))
+	return t.kind + ":" + hex.EncodeToString(sum[:4])
+}
+
+func (t *webhookTarget) Send(ctx context.Context, release string, changes []Change) error {
+	body, err := json.Marshal(t.payload(release, changes))
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		text, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return fmt.Errorf("webhook returned %s: %s", resp.Status, strings.TrimSpace(string(text)))
+	}
+	return nil
+}
+
+func (t *webhookTarget) payload(release string, changes []Change) any {
+	title := fmt.Sprintf("Release %s: %d change(s)", release, len(changes))
+	switch t.kind {
+	case Slack:
+		return map[string]any{
+			"text": title,
+			"blocks": []map[string]any{
+				{"type": "header", "text": map[string]string{"type": "plain_text", "text": title}},
+				{"type": "section", "text": map[string]string{"type": "mrkdwn", "text": chatLines(changes, slackLink)}},
+			},
+		}
+	case GoogleChat:
+		return map[string]string{"text": "*" + title + "*\n" + chatLines(changes, slackLink)}
+	case Teams:
+		return map[string]any{
+			"@type":      "MessageCard",
+			"@context":   "https://schema.org/extensions",
+			"summary":    title,
+			"themeColor": "D70000",
+			"title":      title,
+			"text":       chatLines(changes, markdownLink),
+		}
+	default:
+		return map[string]any{"release": release, "changes": changes}
+	}
+}
+
+// chatLines renders one bullet per change, with the issue key linked.
+func chatLines(changes []Change, link func(text, url string) string) string {
+	var lines []string
+	for i := range changes {
+		if i == maxChangesPerMessage {
+			lines = append(lines, fmt.Sprintf("… and %d more", len(changes)-maxChangesPerMessage))
+			break
+		}
+		change := &changes[i]
+		key := link(change.Key, change.URL)
+		var line string
+		switch change.Kind {
+		case TurnedRed:
+			line = fmt.Sprintf("🔴 %s turned *Red*", key)
+			if change.From != "" {
+				line += " (was " + change.From + ")"
+			}
+		case StatusChanged:
+			line = fmt.Sprintf("🔄 %s moved from %s to *%s*", key, change.From, change.To)
+		default:
+			line = fmt.Sprintf("➖ %s left the release", key)
+		}
+		lines = append(lines, "• "+line+": "+change.Summary)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// slackLink is the link syntax of Slack and Google Chat.
+func slackLink(text, url string) string {
+	return "<" + url + "|" + text + ">"
+}
+
+func markdownLink(text, url string) string {
+	return "[" + text + "](" + url + ")"
+}
@@ -0,0 +1,228 @@
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/edcdavid/jira-helper/internal/reports"
+)
+
+// Kinds of change announced.
+const (
+	TurnedRed     = "turnedRed"
+	StatusChanged = "statusChanged"
+	LeftRelease   = "leftRelease"
+)
+
+const colorRed = "Red"
+
+// Options configures Run.
+type Options struct {
+	Report reports.ReportOptions
+	// StateFile records the last seen values and the changes not delivered yet.
+	StateFile string
+	Interval  time.Duration
+	// Once checks a single time instead of every interval.
+	Once    bool
+	Targets []Target
+}
+
+// Snapshot is the last seen value of an issue.
+type Snapshot struct {
+	Summary string `json:"summary"`
+	URL     string `json:"url"`
+	Color   string `json:"color"`
+	Status  string `json:"status"`
+}
+
+// Change is one change to announce.
+type Change struct {
+	Kind       string    `json:"kind"`
+	Key        string    `json:"key"`
+	Summary    string    `json:"summary"`
+	URL        string    `json:"url"`
+	From       string    `json:"from,omitempty"`
+	To         string    `json:"to,omitempty"`
+	DetectedAt time.Time `json:"detectedAt"`
+}
+
+// state is the content of the state file. Changes are queued per target before being sent,
+// and removed once delivered, so each one is announced once even after a restart or a failed
+// delivery.
+type state struct {
+	Query     string              `json:"query"`
+	CheckedAt time.Time           `json:"checkedAt"`
+	Issues    map[string]Snapshot `json:"issues"`
+	Pending   map[string][]Change `json:"pending"`
+}
+
+// Run checks the report query every interval until ctx is done, and announces the issues that
+// turned red, changed status or left the query to the targets. The first check of a new state
+// file only records the issues.
+func Run(ctx context.Context, opts *Options, out io.Writer) error {
+	query, err := reports.ReportQuery(&opts.Report)
+	if err != nil {
+		return err
+	}
+	if opts.Once {
+		return check(ctx, opts, query, out)
+	}
+	if opts.Interval <= 0 {
+		return fmt.Errorf("invalid interval %s, it must be positive", opts.Interval)
+	}
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+	for {
+		if err := check(ctx, opts, query, out); err != nil {
+			log.Printf("Watch check failed, err:%v", err)
+			fmt.Fprintf(out, "%s check failed: %v\n", time.Now().Format(time.DateTime), err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func check(ctx context.Context, opts *Options, query string, out io.Writer) error {
+	st, err := loadState(opts.StateFile, query)
+	if err != nil {
+		return err
+	}
+	issues, err := reports.FetchReportIssues(ctx, &opts.Report)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	summary := reports.BuildReportSummary(issues, &opts.Report, now)
+
+	current := make(map[string]Snapshot, len(summary.Issues))
+	for _, issue := range summary.Issues {
+		current[issue.Key] = Snapshot{Summary: issue.Summary, URL: issue.URL, Color: issue.Color, Status: issue.Status}
+	}
+
+	first := st.CheckedAt.IsZero()
+	changes := diff(st.Issues, current, now)
+	st.Issues = current
+	st.CheckedAt = now
+	if first {
+		fmt.Fprintf(out, "%s recorded %d issues, changes are announced from the next check\n",
+			now.Format(time.DateTime), len(current))
+		return st.save(opts.StateFile)
+	}
+	for _, target := range opts.Targets {
+		if len(changes) > 0 {
+			st.Pending[target.Name()] = append(st.Pending[target.Name()], changes...)
+		}
+	}
+	// Saved before sending, so the changes are not detected again if the process stops.
+	if err := st.save(opts.StateFile); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "%s %d issues, %d changes\n", now.Format(time.DateTime), len(current), len(changes))
+	for _, change := range changes {
+		fmt.Fprintf(out, "  %s\n", change.text())
+	}
+
+	var sendErrs []error
+	for _, target := range opts.Targets {
+		pending := st.Pending[target.Name()]
+		if len(pending) == 0 {
+			continue
+		}
+		if err := target.Send(ctx, opts.Report.Release, pending); err != nil {
+			sendErrs = append(sendErrs, fmt.Errorf("%s: %w", target.Name(), err))
+			continue
+		}
+		delete(st.Pending, target.Name())
+	}
+	if err := st.save(opts.StateFile); err != nil {
+		return err
+	}
+	return errors.Join(sendErrs...)
+}
+
+// diff returns the changes between two snapshots of the issues, sorted by key.
+func diff(previous, current map[string]Snapshot, now time.Time) []Change {
+	var changes []Change
+	for key, issue := range current {
+		before, seen := previous[key]
+		if issue.Color == colorRed && (!seen || before.Color != colorRed) {
+			changes = append(changes, Change{Kind: TurnedRed, Key: key, Summary: issue.Summary, URL: issue.URL,
+				From: before.Color, To: issue.Color, DetectedAt: now})
+		}
+		if seen && before.Status != issue.Status {
+			changes = append(changes, Change{Kind: StatusChanged, Key: key, Summary: issue.Summary, URL: issue.URL,
+				From: before.Status, To: issue.Status, DetectedAt: now})
+		}
+	}
+	for key, issue := range previous {
+		if _, ok := current[key]; !ok {
+			changes = append(changes, Change{Kind: LeftRelease, Key: key, Summary: issue.Summary, URL: issue.URL,
+				DetectedAt: now})
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+	return changes
+}
+
+// text describes a change in plain text.
+func (c *Change) text() string {
+	switch c.Kind {
+	case TurnedRed:
+		if c.From == "" {
+			return fmt.Sprintf("%s turned Red: %s", c.Key, c.Summary)
+		}
+		return fmt.Sprintf("%s turned Red (was %s): %s", c.Key, c.From, c.Summary)
+	case StatusChanged:
+		return fmt.Sprintf("%s moved from %s to %s: %s", c.Key, c.From, c.To, c.Summary)
+	default:
+		return fmt.Sprintf("%s left the release: %s", c.Key, c.Summary)
+	}
+}
+
+// loadState reads the state file. A missing file, or a file for another query, gives an empty
+// state.
+func loadState(path, query string) (*state, error) {
+	st := &state{Query: query, Issues: map[string]Snapshot{}, Pending: map[string][]Change{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return st, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	loaded := &state{}
+	if err := json.Unmarshal(data, loaded); err != nil {
+		return nil, err
+	}
+	if loaded.Query != query {
+		return st, nil
+	}
+	if loaded.Issues == nil {
+		loaded.Issues = map[string]Snapshot{}
+	}
+	if loaded.Pending == nil {
+		loaded.Pending = map[string][]Change{}
+	}
+	return loaded, nil
+}
+
+func (s *state) save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600) //nolint:mnd
+}
@@ -0,0 +1,198 @@
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edcdavid/jira-helper/internal/jirahelper"
+	"github.com/edcdavid/jira-helper/internal/reports"
+)
+
+func TestDiff(t *testing.T) {
+	now := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+	green := Snapshot{Summary: "summary", URL: "https://issues.example.com/browse/CNF-1", Color: "Green", Status: "New"}
+	red := green
+	red.Color = colorRed
+	moved := green
+	moved.Status = "In Progress"
+
+	tests := []struct {
+		name     string
+		previous map[string]Snapshot
+		current  map[string]Snapshot
+		want     []Change
+	}{
+		{
+			name:     "unchanged",
+			previous: map[string]Snapshot{"CNF-1": green, "CNF-2": red},
+			current:  map[string]Snapshot{"CNF-1": green, "CNF-2": red},
+		},
+		{
+			name:     "turned red",
+			previous: map[string]Snapshot{"CNF-1": green},
+			current:  map[string]Snapshot{"CNF-1": red},
+			want:     []Change{{Kind: TurnedRed, Key: "CNF-1", From: "Green", To: colorRed}},
+		},
+		{
+			name:     "new red issue",
+			previous: map[string]Snapshot{},
+			current:  map[string]Snapshot{"CNF-1": red, "CNF-2": green},
+			want:     []Change{{Kind: TurnedRed, Key: "CNF-1", To: colorRed}},
+		},
+		{
+			name:     "status changed",
+			previous: map[string]Snapshot{"CNF-1": green},
+			current:  map[string]Snapshot{"CNF-1": moved},
+			want:     []Change{{Kind: StatusChanged, Key: "CNF-1", From: "New", To: "In Progress"}},
+		},
+		{
+			name:     "left the release",
+			previous: map[string]Snapshot{"CNF-1": green, "CNF-2": green},
+			current:  map[string]Snapshot{"CNF-1": green},
+			want:     []Change{{Kind: LeftRelease, Key: "CNF-2"}},
+		},
+		{
+			name:     "sorted by key",
+			previous: map[string]Snapshot{"CNF-3": green, "CNF-2": green, "CNF-1": green},
+			current:  map[string]Snapshot{"CNF-1": moved, "CNF-2": red},
+			want: []Change{
+				{Kind: StatusChanged, Key: "CNF-1", From: "New", To: "In Progress"},
+				{Kind: TurnedRed, Key: "CNF-2", From: "Green", To: colorRed},
+				{Kind: LeftRelease, Key: "CNF-3"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := diff(tt.previous, tt.current, now)
+			if len(got) != len(tt.want) {
+				t.Fatalf("diff = %+v, want %+v", got, tt.want)
+			}
+			for i, want := range tt.want {
+				want.Summary, want.URL, want.DetectedAt = green.Summary, green.URL, now
+				if got[i] != want {
+					t.Errorf("change %d = %+v, want %+v", i, got[i], want)
+				}
+			}
+		})
+	}
+}
+
+// fakeWebhook records the changes posted by a generic target, after rejecting the first
+// failures posts.
+type fakeWebhook struct {
+	mu       sync.Mutex
+	failures int
+	posts    int
+	received []Change
+}
+
+func (f *fakeWebhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.posts++
+	if f.failures > 0 {
+		f.failures--
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	var body struct {
+		Changes []Change `json:"changes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f.received = append(f.received, body.Changes...)
+}
+
+func TestRunDeliversPendingChangesOnce(t *testing.T) {
+	var mu sync.Mutex
+	colors := map[string]string{"CNF-1": "Green", "CNF-2": "Green"}
+	jiraServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		var issues []map[string]any
+		for _, key := range []string{"CNF-1", "CNF-2"} {
+			issues = append(issues, map[string]any{"key": key, "fields": map[string]any{
+				"summary":             "summary of " + key,
+				"status":              map[string]string{"name": "New"},
+				jirahelper.ColorField: map[string]string{"value": colors[key]},
+			}})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"startAt": 0, "maxResults": len(issues),
+			"total": len(issues), "issues": issues})
+	}))
+	defer jiraServer.Close()
+
+	webhook := &fakeWebhook{failures: 1}
+	webhookServer := httptest.NewServer(webhook)
+	defer webhookServer.Close()
+	target, err := NewTarget(Generic, webhookServer.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := &Options{
+		Report:    reports.ReportOptions{JiraURL: jiraServer.URL, FilterQuery: "project = CNF", Release: "4.20"},
+		StateFile: filepath.Join(t.TempDir(), "watch.json"),
+		Once:      true,
+		Targets:   []Target{target},
+	}
+	ctx := context.Background()
+
+	// The first check only records the issues.
+	if err := Run(ctx, opts, io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	colors["CNF-1"] = colorRed
+	mu.Unlock()
+
+	// The change is queued in the state file, and the failed delivery is reported.
+	if err := Run(ctx, opts, io.Discard); err == nil {
+		t.Fatal("Run with a failing webhook returned no error")
+	}
+	st, err := loadState(opts.StateFile, opts.Report.FilterQuery)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pending := st.Pending[target.Name()]; len(pending) != 1 || pending[0].Key != "CNF-1" {
+		t.Errorf("pending changes = %+v, want CNF-1 turning red", pending)
+	}
+
+	// The next checks deliver the pending change without detecting it again.
+	for range 2 {
+		if err := Run(ctx, opts, io.Discard); err != nil {
+			t.Fatal(err)
+		}
+	}
+	webhook.mu.Lock()
+	defer webhook.mu.Unlock()
+	if webhook.posts != 2 {
+		t.Errorf("webhook posts = %d, want 2", webhook.posts)
+	}
+	if len(webhook.received) != 1 || webhook.received[0].Kind != TurnedRed || webhook.received[0].Key != "CNF-1" {
+		t.Errorf("received = %+v, want CNF-1 turning red once", webhook.received)
+	}
+	st, err = loadState(opts.StateFile, opts.Report.FilterQuery)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(st.Pending) != 0 {
+		t.Errorf("pending after delivery = %+v", st.Pending)
+	}
+}
+
+func TestRunRejectsInterval(t *testing.T) {
+	opts := &Options{Report: reports.ReportOptions{FilterQuery: "project = CNF"}}
+	if err := Run(context.Background(), opts, io.Discard); err == nil {
+		t.Error("Run without an interval returned no error")
+	}
+}